@@ -0,0 +1,174 @@
+// Package chess960 provides helpers for Chess960 (Fischer Random) start positions.
+//
+// Start positions are identified by their Scharnagl number, an integer between 0 and 959
+// inclusive. The standard chess start position is number 518.
+package chess960
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Count is the number of distinct Chess960 start positions
+const Count = 960
+
+// Standard is the Scharnagl number of the standard chess start position
+const Standard = 518
+
+// knightTable lists the two empty squares (out of the five left after placing the bishops and
+// queen) that the knights occupy for each remaining value of the Scharnagl number.
+var knightTable = [10][2]int{
+	{0, 1}, {0, 2}, {0, 3}, {0, 4},
+	{1, 2}, {1, 3}, {1, 4},
+	{2, 3}, {2, 4},
+	{3, 4},
+}
+
+// ErrInvalidPosition is returned when a back rank is not a legal Chess960 start position
+var ErrInvalidPosition = errors.New("not a chess960 start position")
+
+// BackRank returns the white back rank of the start position with Scharnagl number n as a string
+// of piece letters from the a file to the h file, ie. BackRank(518) returns "RNBQKBNR".
+func BackRank(n int) (string, error) {
+	if n < 0 || n >= Count {
+		return "", fmt.Errorf("chess960 number %v out of range 0-%v", n, Count-1)
+	}
+
+	var rank [8]byte
+
+	// Light squared bishop on b, d, f or h
+	rank[(n%4)*2+1] = 'B'
+	n /= 4
+	// Dark squared bishop on a, c, e or g
+	rank[(n%4)*2] = 'B'
+	n /= 4
+
+	place(&rank, n%6, 'Q')
+	n /= 6
+
+	// The second knight is placed first so the index of the first is not shifted
+	place(&rank, knightTable[n][1], 'N')
+	place(&rank, knightTable[n][0], 'N')
+
+	// Rook, king and rook fill the remaining squares from left to right
+	for _, piece := range []byte("RKR") {
+		place(&rank, 0, piece)
+	}
+
+	return string(rank[:]), nil
+}
+
+// place puts a piece on the i-th empty square of a rank
+func place(rank *[8]byte, i int, piece byte) {
+	for file := range rank {
+		if rank[file] != 0 {
+			continue
+		}
+		if i == 0 {
+			rank[file] = piece
+			return
+		}
+		i--
+	}
+}
+
+// Number returns the Scharnagl number of a back rank. The rank may be given in either upper or
+// lower case letters from the a file to the h file.
+func Number(backRank string) (int, error) {
+	rank := strings.ToUpper(backRank)
+	if len(rank) != 8 {
+		return 0, ErrInvalidPosition
+	}
+
+	var counts = make(map[rune]int)
+	for _, ch := range rank {
+		counts[ch]++
+	}
+	if counts['K'] != 1 || counts['Q'] != 1 || counts['R'] != 2 || counts['B'] != 2 || counts['N'] != 2 {
+		return 0, ErrInvalidPosition
+	}
+
+	// The king must sit between the rooks
+	first := strings.IndexByte(rank, 'R')
+	last := strings.LastIndexByte(rank, 'R')
+	king := strings.IndexByte(rank, 'K')
+	if king < first || king > last {
+		return 0, ErrInvalidPosition
+	}
+
+	var light, dark = -1, -1
+	var rest []byte
+	for file := 0; file < 8; file++ {
+		if rank[file] != 'B' {
+			rest = append(rest, rank[file])
+			continue
+		}
+		if file%2 == 1 {
+			light = file / 2
+		} else {
+			dark = file / 2
+		}
+	}
+	// Both bishops on the same colour
+	if light < 0 || dark < 0 {
+		return 0, ErrInvalidPosition
+	}
+
+	queen := strings.IndexByte(string(rest), 'Q')
+	rest = append(rest[:queen], rest[queen+1:]...)
+
+	var knights [2]int
+	var k int
+	for i, ch := range rest {
+		if ch == 'N' {
+			knights[k] = i
+			k++
+		}
+	}
+
+	var kn int
+	for i, pair := range knightTable {
+		if pair == knights {
+			kn = i
+			break
+		}
+	}
+
+	return ((kn*6+queen)*4+dark)*4 + light, nil
+}
+
+// FEN returns the starting FEN of the Chess960 position with Scharnagl number n. Castling rights
+// are written in the X-FEN style (KQkq) which is what lichess uses in its exports.
+func FEN(n int) (string, error) {
+	rank, err := BackRank(n)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/pppppppp/8/8/8/8/PPPPPPPP/%s w KQkq - 0 1", strings.ToLower(rank), rank), nil
+}
+
+// FromFEN returns the Scharnagl number of a FEN if it describes a Chess960 start position.
+// Only the piece placement field is inspected.
+func FromFEN(fen string) (int, error) {
+	fields := strings.Fields(fen)
+	if len(fields) == 0 {
+		return 0, ErrInvalidPosition
+	}
+
+	ranks := strings.Split(fields[0], "/")
+	if len(ranks) != 8 || ranks[1] != "pppppppp" || ranks[6] != "PPPPPPPP" {
+		return 0, ErrInvalidPosition
+	}
+	for _, r := range ranks[2:6] {
+		if r != "8" {
+			return 0, ErrInvalidPosition
+		}
+	}
+	if ranks[7] != strings.ToUpper(ranks[7]) || ranks[0] != strings.ToLower(ranks[7]) {
+		return 0, ErrInvalidPosition
+	}
+
+	return Number(ranks[7])
+}
@@ -0,0 +1,122 @@
+package chess960
+
+import "testing"
+
+func TestBackRank(t *testing.T) {
+	tests := []struct {
+		name    string
+		n       int
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "First position",
+			n:    0,
+			want: "BBQNNRKR",
+		},
+		{
+			name: "Standard position",
+			n:    518,
+			want: "RNBQKBNR",
+		},
+		{
+			name: "Last position",
+			n:    959,
+			want: "RKRNNQBB",
+		},
+		{
+			name:    "Negative number",
+			n:       -1,
+			wantErr: true,
+		},
+		{
+			name:    "Number too large",
+			n:       960,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BackRank(tt.n)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("BackRank() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("BackRank() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNumber(t *testing.T) {
+	tests := []struct {
+		name     string
+		backRank string
+		want     int
+		wantErr  bool
+	}{
+		{
+			name:     "Standard position",
+			backRank: "RNBQKBNR",
+			want:     518,
+		},
+		{
+			name:     "Lower case",
+			backRank: "bbqnnrkr",
+			want:     0,
+		},
+		{
+			name:     "Bishops on the same colour",
+			backRank: "BNBQKRNR",
+			wantErr:  true,
+		},
+		{
+			name:     "King outside the rooks",
+			backRank: "KRBQRBNN",
+			wantErr:  true,
+		},
+		{
+			name:     "Missing piece",
+			backRank: "RNBQKBN",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Number(tt.backRank)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Number() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Number() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	for n := 0; n < Count; n++ {
+		fen, err := FEN(n)
+		if err != nil {
+			t.Fatalf("FEN(%v) returned error %v", n, err)
+		}
+		got, err := FromFEN(fen)
+		if err != nil {
+			t.Fatalf("FromFEN(%v) returned error %v", fen, err)
+		}
+		if got != n {
+			t.Errorf("FromFEN(FEN(%v)) = %v", n, got)
+		}
+	}
+}
+
+func TestFromFEN(t *testing.T) {
+	if _, err := FromFEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1"); err == nil {
+		t.Errorf("FromFEN() expected an error for a position after 1. e4")
+	}
+	if _, err := FromFEN("RNBQKBNR/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"); err == nil {
+		t.Errorf("FromFEN() expected an error for an upper case black back rank")
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/schafer14/go-chess/polyglot"
+)
+
+// startFEN is the standard start position
+const startFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// bookCmd prints the moves a Polyglot opening book has for a position with their weights.
+//
+//	pgn book [-fen fen] book.bin
+func bookCmd(args []string) {
+	flags := flag.NewFlagSet("book", flag.ExitOnError)
+	fen := flags.String("fen", startFEN, "The position to look up")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		log.Fatal("Expected a single book file")
+	}
+	key, err := polyglot.Key(*fen)
+	if err != nil {
+		log.Fatal(err)
+	}
+	book, err := polyglot.Open(flags.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	entries := book.Entries(key)
+	if len(entries) == 0 {
+		fmt.Fprintf(os.Stderr, "No moves for key %016x\n", key)
+		return
+	}
+
+	var total int
+	for _, e := range entries {
+		total += int(e.Weight)
+	}
+	for _, e := range entries {
+		share := 0.0
+		if total > 0 {
+			share = 100 * float64(e.Weight) / float64(total)
+		}
+		fmt.Printf("%-6s %6d %5.1f%%\n", e.UCI(), e.Weight, share)
+	}
+}
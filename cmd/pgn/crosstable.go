@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/schafer14/go-chess/tournament"
+)
+
+// crosstableCmd prints the standings and crosstable of every event in pgn files.
+//
+//	pgn crosstable [-format text|csv|html] [-style auto|grid|swiss] [-event name] [-tiebreaks BH-C1,BH,SB] games.pgn
+func crosstableCmd(args []string) {
+	flags := flag.NewFlagSet("crosstable", flag.ExitOnError)
+	format := flags.String("format", "text", "The output format: text, csv or html")
+	styleName := flags.String("style", "auto", "The table layout: auto, grid or swiss")
+	event := flags.String("event", "", "Only include games with this Event tag")
+	tiebreakList := flags.String("tiebreaks", "", "Comma separated tiebreaks from BH, BH-C1, BH-C2, BH-M, SB, DE, WIN and ARO")
+	flags.Parse(args)
+
+	var style tournament.Style
+	switch *styleName {
+	case "auto":
+		style = tournament.Auto
+	case "grid":
+		style = tournament.Grid
+	case "swiss":
+		style = tournament.Swiss
+	default:
+		log.Fatalf("Unknown style %q", *styleName)
+	}
+
+	var tiebreaks []tournament.Tiebreak
+	if *tiebreakList != "" {
+		for _, name := range strings.Split(*tiebreakList, ",") {
+			tb, err := tournament.ParseTiebreak(strings.TrimSpace(name))
+			if err != nil {
+				log.Fatal(err)
+			}
+			tiebreaks = append(tiebreaks, tb)
+		}
+	}
+
+	events, byEvent := tournament.ByEvent(parseFiles(flags.Args()))
+	if *event != "" {
+		if _, ok := byEvent[*event]; !ok {
+			log.Fatalf("No games found for event %q", *event)
+		}
+		events = []string{*event}
+	}
+
+	for i, name := range events {
+		t := tournament.FromGames(byEvent[name])
+
+		tbs := tiebreaks
+		if tbs == nil {
+			tbs = tournament.DefaultSwissTiebreaks
+			if style == tournament.Grid || (style == tournament.Auto && t.IsRoundRobin()) {
+				tbs = tournament.DefaultRoundRobinTiebreaks
+			}
+		}
+		ct := t.Crosstable(style, tbs)
+
+		if i > 0 && *format != "csv" {
+			fmt.Println()
+		}
+
+		var err error
+		switch *format {
+		case "text":
+			err = ct.WriteText(os.Stdout)
+		case "csv":
+			err = ct.WriteCSV(os.Stdout)
+		case "html":
+			err = ct.WriteHTML(os.Stdout)
+		default:
+			log.Fatalf("Unknown format %q", *format)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
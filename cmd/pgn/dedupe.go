@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/schafer14/go-chess/dedupe"
+	"github.com/schafer14/go-chess/pgn"
+)
+
+// dedupeCmd removes duplicate games from pgn files, writing the remaining games and a report of
+// what was removed.
+//
+//	pgn dedupe -out clean.pgn -report report.txt games1.pgn games2.pgn
+func dedupeCmd(args []string) {
+	flags := flag.NewFlagSet("dedupe", flag.ExitOnError)
+	out := flags.String("out", "", "The file to write the remaining games to. Defaults to stdout")
+	report := flags.String("report", "", "The file to write the report to. Defaults to stderr")
+	policy := flags.String("keep", "complete", "Which copy to keep: first, complete or annotated")
+	names := flags.Float64("names", dedupe.DefaultOptions.NameSimilarity, "The minimum similarity of player names between 0 and 1")
+	days := flags.Int("days", 1, "The maximum number of days between the dates of duplicates")
+	flags.Parse(args)
+
+	opts := dedupe.Options{
+		NameSimilarity: *names,
+		MaxDateDiff:    time.Duration(*days) * 24 * time.Hour,
+	}
+	switch *policy {
+	case "first":
+		opts.Policy = dedupe.KeepFirst
+	case "complete":
+		opts.Policy = dedupe.KeepMostComplete
+	case "annotated":
+		opts.Policy = dedupe.KeepMostAnnotated
+	default:
+		log.Fatalf("Unknown keep policy %q", *policy)
+	}
+
+	games := parseFiles(flags.Args())
+	kept, groups := dedupe.Remove(games, opts)
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := pgn.Write(w, kept); err != nil {
+		log.Fatal(err)
+	}
+
+	var r io.Writer = os.Stderr
+	if *report != "" {
+		file, err := os.Create(*report)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+		r = file
+	}
+	for _, g := range groups {
+		fmt.Fprintf(r, "kept   #%d %s\n", g.Kept+1, describe(games[g.Kept]))
+		for _, i := range g.Duplicates {
+			fmt.Fprintf(r, "  drop #%d %s\n", i+1, describe(games[i]))
+		}
+	}
+	fmt.Fprintf(r, "%d games read, %d duplicates removed, %d games written\n", len(games), len(games)-len(kept), len(kept))
+}
+
+// describe returns a short description of a game for reports
+func describe(g pgn.Game) string {
+	return fmt.Sprintf("%s - %s, %s %s (%s)", g.Tags["White"], g.Tags["Black"], g.Tags["Event"], g.Tags["Date"], g.Tags["Result"])
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/schafer14/go-chess/explorer"
+	"github.com/schafer14/go-chess/pgn"
+)
+
+// explore builds an opening tree from pgn files and saved trees and prints the moves played
+// from a position.
+//
+//	pgn explore -moves "1. e4 c5" games1.pgn games2.pgn
+func explore(args []string) {
+	flags := flag.NewFlagSet("explore", flag.ExitOnError)
+	moves := flags.String("moves", "", "The moves leading to the position to show, ie. \"1. e4 c5\"")
+	maxPly := flags.Int("ply", 30, "The number of half moves of each game to record")
+	load := flags.String("load", "", "A comma separated list of saved trees to merge in")
+	save := flags.String("save", "", "A file to save the tree to")
+	flags.Parse(args)
+
+	tree := explorer.New(*maxPly)
+	tree.AddAll(parseFiles(flags.Args()))
+
+	if *load != "" {
+		for _, filePath := range strings.Split(*load, ",") {
+			file, err := os.Open(filePath)
+			if err != nil {
+				log.Fatal(err)
+			}
+			other, err := explorer.Load(file)
+			file.Close()
+			if err != nil {
+				log.Fatalf("%s: %v", filePath, err)
+			}
+			tree.Merge(other)
+		}
+	}
+
+	if *save != "" {
+		file, err := os.Create(*save)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := tree.Save(file); err != nil {
+			log.Fatal(err)
+		}
+		if err := file.Close(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	node, ok := tree.Lookup(moveList(*moves))
+	if !ok {
+		fmt.Println("Position not found")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Move\tGames\tWhite\tDraw\tBlack\tAvg Elo\tPerf\tLast played\t")
+	for _, m := range node.Moves() {
+		printStats(w, m.Move, m.Stats)
+	}
+	// The ratings of a node belong to the side that moved into it, so they're left off the total
+	total := node.Stats
+	total.RatedGames = 0
+	printStats(w, "Σ", total)
+	w.Flush()
+}
+
+func printStats(w *tabwriter.Writer, move string, s explorer.Stats) {
+	fmt.Fprintf(w, "%s\t%d\t%.0f%%\t%.0f%%\t%.0f%%\t%s\t%s\t%s\t\n",
+		move, s.Games, s.WhitePercent(), s.DrawPercent(), s.BlackPercent(),
+		rating(s.AverageElo()), rating(s.Performance()), s.LastPlayed)
+}
+
+func rating(r int) string {
+	if r == 0 {
+		return "-"
+	}
+	return fmt.Sprint(r)
+}
+
+// moveList extracts the moves from movetext, dropping move numbers
+func moveList(movetext string) []string {
+	var s pgn.Scanner
+	s.Init(strings.NewReader(movetext))
+
+	var moves []string
+	for {
+		tok := s.Next()
+		if tok.Tok == pgn.EOF || tok.Tok == pgn.Illegal {
+			return moves
+		}
+		if tok.Tok == pgn.Ident {
+			moves = append(moves, tok.Literal)
+		}
+	}
+}
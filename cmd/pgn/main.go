@@ -11,6 +11,32 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "explore":
+			explore(os.Args[2:])
+			return
+		case "dedupe":
+			dedupeCmd(os.Args[2:])
+			return
+		case "stats":
+			statsCmd(os.Args[2:])
+			return
+		case "crosstable":
+			crosstableCmd(os.Args[2:])
+			return
+		case "trf":
+			trfCmd(os.Args[2:])
+			return
+		case "timeuse":
+			timeuseCmd(os.Args[2:])
+			return
+		case "book":
+			bookCmd(os.Args[2:])
+			return
+		}
+	}
+
 	filePath := flag.String("file", "", "The pgn file to parse")
 	runSync := flag.Bool("sync", false, "Forces the process to run without concurrency")
 
@@ -40,3 +66,22 @@ func main() {
 
 	return
 }
+
+// parseFiles parses every game in a list of pgn files. Games that fail to parse are skipped
+// and reported on stderr.
+func parseFiles(filePaths []string) []pgn.Game {
+	var games []pgn.Game
+	for _, filePath := range filePaths {
+		file, err := os.Open(filePath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		parsed, err := pgn.Parse(file)
+		file.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", filePath, err)
+		}
+		games = append(games, parsed...)
+	}
+	return games
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/schafer14/go-chess/stats"
+)
+
+// statsCmd prints the statistics of a player across pgn files.
+//
+//	pgn stats -player "Carlsen, Magnus" [-json] games.pgn
+func statsCmd(args []string) {
+	flags := flag.NewFlagSet("stats", flag.ExitOnError)
+	player := flags.String("player", "", "The name of the player as it appears in the White and Black tags")
+	asJSON := flags.Bool("json", false, "Print the statistics as JSON")
+	flags.Parse(args)
+
+	if *player == "" {
+		log.Fatal("The -player flag is required")
+	}
+
+	p := stats.ForPlayer(parseFiles(flags.Args()), *player)
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(p); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Player\t%s\n", p.Name)
+	fmt.Fprintf(w, "Games\t%d (%s to %s)\n", p.Games, p.FirstGame, p.LastGame)
+	fmt.Fprintf(w, "Average opponent\t%s\n", rating(p.AverageOpponentElo))
+	fmt.Fprintf(w, "Performance\t%s\n", rating(p.Performance))
+	fmt.Fprintf(w, "Longest streaks\t%d wins, %d unbeaten, %d losses\n", p.LongestWinStreak, p.LongestUnbeaten, p.LongestLosingStreak)
+	fmt.Fprintf(w, "Current streak\t%d%s\n", p.CurrentStreak.Length, p.CurrentStreak.Result)
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "\tGames\t+\t=\t-\tScore\t%")
+	printRecord(w, "Total", p.Record)
+	printRecord(w, "White", p.White)
+	printRecord(w, "Black", p.Black)
+	fmt.Fprintln(w)
+
+	printRecords(w, "ECO", p.ByECO)
+	fmt.Fprintln(w)
+	printRecords(w, "Time control", p.ByTimeControl)
+	w.Flush()
+}
+
+func printRecord(w *tabwriter.Writer, name string, r stats.Record) {
+	fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%g\t%.1f\n", name, r.Games, r.Wins, r.Draws, r.Losses, r.Score(), r.Percent())
+}
+
+// printRecords prints a table of records, most played first
+func printRecords(w *tabwriter.Writer, title string, records map[string]stats.Record) {
+	var keys []string
+	for k := range records {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if records[keys[i]].Games != records[keys[j]].Games {
+			return records[keys[i]].Games > records[keys[j]].Games
+		}
+		return keys[i] < keys[j]
+	})
+
+	fmt.Fprintf(w, "%s\tGames\t+\t=\t-\tScore\t%%\n", title)
+	for _, k := range keys {
+		printRecord(w, k, records[k])
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+
+	"github.com/schafer14/go-chess/timeuse"
+)
+
+// timeuseCmd prints where players spent their time in games with clock comments.
+//
+//	pgn timeuse [-by player|game] [-player name] [-format csv|json] games.pgn
+func timeuseCmd(args []string) {
+	flags := flag.NewFlagSet("timeuse", flag.ExitOnError)
+	by := flags.String("by", "player", "Summarise by player, or by game with a row for each side")
+	player := flags.String("player", "", "Only include this player")
+	format := flags.String("format", "csv", "The output format: csv or json")
+	opening := flags.Int("opening", timeuse.DefaultOptions.OpeningMoves, "The last move of the opening")
+	endgame := flags.Int("endgame", timeuse.DefaultOptions.EndgameMoves, "The last move of the middlegame")
+	trouble := flags.Float64("trouble", timeuse.DefaultOptions.TimeTrouble, "The share of the initial time below which a player is in time trouble")
+	flags.Parse(args)
+
+	opts := timeuse.Options{OpeningMoves: *opening, EndgameMoves: *endgame, TimeTrouble: *trouble}
+	games := parseFiles(flags.Args())
+
+	var summaries []timeuse.Summary
+	switch *by {
+	case "player":
+		players, skipped := timeuse.Players(games, opts)
+		if skipped > 0 {
+			fmt.Fprintf(os.Stderr, "Skipped %d games without clock data\n", skipped)
+		}
+		var names []string
+		for name := range players {
+			if *player == "" || name == *player {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			summaries = append(summaries, players[name].Summary())
+		}
+	case "game":
+		for i, g := range games {
+			white, black, err := timeuse.Game(g, opts)
+			if err != nil {
+				continue
+			}
+			for _, u := range []timeuse.Usage{white, black} {
+				if *player != "" && u.Player != *player {
+					continue
+				}
+				s := u.Summary()
+				s.Game = fmt.Sprint(i + 1)
+				summaries = append(summaries, s)
+			}
+		}
+	default:
+		log.Fatalf("Unknown summary %q", *by)
+	}
+
+	switch *format {
+	case "csv":
+		if err := timeuse.WriteCSV(os.Stdout, summaries); err != nil {
+			log.Fatal(err)
+		}
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(summaries); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatalf("Unknown format %q", *format)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/schafer14/go-chess/pgn"
+	"github.com/schafer14/go-chess/tournament"
+	"github.com/schafer14/go-chess/trf"
+)
+
+// trfCmd converts between pgn files and FIDE tournament report files.
+//
+//	pgn trf [-event name] games.pgn > report.trf
+//	pgn trf -read report.trf > games.pgn
+func trfCmd(args []string) {
+	flags := flag.NewFlagSet("trf", flag.ExitOnError)
+	event := flags.String("event", "", "The event to report when the pgn files have games from more than one")
+	read := flags.String("read", "", "A report file to convert to pgn game stubs")
+	flags.Parse(args)
+
+	if *read != "" {
+		file, err := os.Open(*read)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer file.Close()
+
+		report, err := trf.Read(file)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := pgn.Write(os.Stdout, report.Games()); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	events, byEvent := tournament.ByEvent(parseFiles(flags.Args()))
+	name := *event
+	switch {
+	case name != "":
+		if _, ok := byEvent[name]; !ok {
+			log.Fatalf("No games found for event %q", name)
+		}
+	case len(events) == 1:
+		name = events[0]
+	case len(events) == 0:
+		log.Fatal("No games found")
+	default:
+		log.Fatalf("The games are from more than one event, choose one with -event: %s", strings.Join(events, ", "))
+	}
+
+	if err := trf.FromGames(byEvent[name]).Write(os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,385 @@
+// Package dedupe finds and removes duplicate games in a collection.
+//
+// Two games are duplicates when they have the same moves and result and their tags are close
+// enough to be the same game recorded by different sources: player names may be abbreviated or
+// written in a different order and dates may be missing or a little off. Games without moves,
+// such as forfeits and pairing stubs, are never duplicates.
+package dedupe
+
+import (
+	"hash/fnv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/schafer14/go-chess/pgn"
+)
+
+// Policy decides which copy of a duplicated game is kept
+type Policy int
+
+const (
+	// KeepFirst keeps the copy that appears first in the input
+	KeepFirst Policy = iota
+	// KeepMostComplete keeps the copy with the most known tags, breaking ties on annotations
+	KeepMostComplete
+	// KeepMostAnnotated keeps the copy with the most comments, NAGs and variations, breaking ties
+	// on known tags
+	KeepMostAnnotated
+)
+
+// Options configure how similar games must be to count as duplicates
+type Options struct {
+	// NameSimilarity is the minimum similarity, between 0 and 1, for two player names that are
+	// not an abbreviation of each other to be considered the same player.
+	NameSimilarity float64
+	// MaxDateDiff is the largest difference between two complete dates of the same game
+	MaxDateDiff time.Duration
+	// Policy decides which copy is kept
+	Policy Policy
+}
+
+// DefaultOptions are sensible options for merged databases
+var DefaultOptions = Options{
+	NameSimilarity: 0.85,
+	MaxDateDiff:    24 * time.Hour,
+	Policy:         KeepMostComplete,
+}
+
+// Group is a set of games that are copies of each other. Indexes refer to the input list.
+type Group struct {
+	Kept       int
+	Duplicates []int
+}
+
+// MoveHash returns a hash of a game's moves. Check and mate symbols are ignored as sources
+// don't always agree on them.
+func MoveHash(game pgn.Game) uint64 {
+	h := fnv.New64a()
+	for _, m := range game.Moves {
+		h.Write([]byte(strings.TrimRight(m.Move, "+#")))
+		h.Write([]byte{' '})
+	}
+	return h.Sum64()
+}
+
+// Find returns the groups of duplicate games. Games without duplicates are not included.
+func Find(games []pgn.Game, opts Options) []Group {
+	buckets := make(map[uint64][]int)
+	var order []uint64
+	for i, g := range games {
+		if len(g.Moves) == 0 {
+			continue
+		}
+		key := MoveHash(g)
+		if _, ok := buckets[key]; !ok {
+			order = append(order, key)
+		}
+		buckets[key] = append(buckets[key], i)
+	}
+
+	var groups []Group
+	for _, key := range order {
+		// Each cluster is a list of indexes, the first one is compared against new games
+		var clusters [][]int
+		for _, i := range buckets[key] {
+			placed := false
+			for c := range clusters {
+				if Same(games[clusters[c][0]], games[i], opts) {
+					clusters[c] = append(clusters[c], i)
+					placed = true
+					break
+				}
+			}
+			if !placed {
+				clusters = append(clusters, []int{i})
+			}
+		}
+
+		for _, cluster := range clusters {
+			if len(cluster) > 1 {
+				groups = append(groups, newGroup(games, cluster, opts.Policy))
+			}
+		}
+	}
+
+	return groups
+}
+
+// Remove returns the games with all duplicates removed, keeping the input order, along with
+// the groups that were found.
+func Remove(games []pgn.Game, opts Options) ([]pgn.Game, []Group) {
+	groups := Find(games, opts)
+
+	dropped := make(map[int]bool)
+	for _, g := range groups {
+		for _, i := range g.Duplicates {
+			dropped[i] = true
+		}
+	}
+
+	var kept []pgn.Game
+	for i, g := range games {
+		if !dropped[i] {
+			kept = append(kept, g)
+		}
+	}
+
+	return kept, groups
+}
+
+func newGroup(games []pgn.Game, cluster []int, policy Policy) Group {
+	best := cluster[0]
+	for _, i := range cluster[1:] {
+		if better(games[i], games[best], policy) {
+			best = i
+		}
+	}
+
+	group := Group{Kept: best}
+	for _, i := range cluster {
+		if i != best {
+			group.Duplicates = append(group.Duplicates, i)
+		}
+	}
+	return group
+}
+
+// better reports whether a should be kept over b
+func better(a, b pgn.Game, policy Policy) bool {
+	ta, tb := knownTags(a), knownTags(b)
+	aa, ab := annotations(a.Moves), annotations(b.Moves)
+
+	switch policy {
+	case KeepMostComplete:
+		return ta > tb || (ta == tb && aa > ab)
+	case KeepMostAnnotated:
+		return aa > ab || (aa == ab && ta > tb)
+	}
+	return false
+}
+
+// knownTags counts the tags with a value that isn't blank or unknown
+func knownTags(g pgn.Game) int {
+	var n int
+	for _, v := range g.Tags {
+		if !unknown(v) {
+			n++
+		}
+	}
+	return n
+}
+
+func annotations(moves []pgn.Move) int {
+	var n int
+	for _, m := range moves {
+		if m.Annotation != "" {
+			n++
+		}
+		if m.Nag != "" {
+			n++
+		}
+		if len(m.Alternatives) > 0 {
+			n += 1 + annotations(m.Alternatives)
+		}
+	}
+	return n
+}
+
+// Same reports whether two games are the same game based on their moves and tags. Games
+// without moves are never the same as they can't be told apart from other unplayed games
+// between the same players.
+func Same(a, b pgn.Game, opts Options) bool {
+	if len(a.Moves) == 0 || len(b.Moves) == 0 || MoveHash(a) != MoveHash(b) {
+		return false
+	}
+
+	if !sameResult(a.Tags["Result"], b.Tags["Result"]) {
+		return false
+	}
+
+	if !unknown(a.Tags["Round"]) && !unknown(b.Tags["Round"]) && a.Tags["Round"] != b.Tags["Round"] {
+		return false
+	}
+
+	if !SamePlayer(a.Tags["White"], b.Tags["White"], opts.NameSimilarity) ||
+		!SamePlayer(a.Tags["Black"], b.Tags["Black"], opts.NameSimilarity) {
+		return false
+	}
+
+	return closeDates(date(a.Tags), date(b.Tags), opts.MaxDateDiff)
+}
+
+func sameResult(a, b string) bool {
+	if unknown(a) || unknown(b) || a == "*" || b == "*" {
+		return true
+	}
+	return a == b
+}
+
+// SamePlayer reports whether two player names could belong to the same person. Unknown names
+// match anything. Names match when one is an abbreviation of the other, ie. "Carlsen, M" and
+// "Magnus Carlsen", or when their similarity is at least minSimilarity.
+func SamePlayer(a, b string, minSimilarity float64) bool {
+	if unknown(a) || unknown(b) {
+		return true
+	}
+
+	wa, wb := nameWords(a), nameWords(b)
+	if strings.Join(wa, " ") == strings.Join(wb, " ") {
+		return true
+	}
+	if abbreviates(wa, wb) || abbreviates(wb, wa) {
+		return true
+	}
+
+	return similarity(strings.Join(wa, " "), strings.Join(wb, " ")) >= minSimilarity
+}
+
+// nameWords splits a name into lower case words dropping punctuation
+func nameWords(name string) []string {
+	return strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// abbreviates reports whether every word of short matches a different word of long, either
+// exactly or as an initial, and at least one word matches in full.
+func abbreviates(short, long []string) bool {
+	if len(short) == 0 || len(short) > len(long) {
+		return false
+	}
+
+	used := make([]bool, len(long))
+	full := false
+	for _, s := range short {
+		matched := false
+		// Prefer full matches so an initial doesn't take the word a surname needs
+		for pass := 0; pass < 2 && !matched; pass++ {
+			for i, l := range long {
+				if used[i] {
+					continue
+				}
+				if s == l || (pass == 1 && len(s) == 1 && strings.HasPrefix(l, s)) {
+					used[i] = true
+					matched = true
+					if s == l && len(s) > 1 {
+						full = true
+					}
+					break
+				}
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return full
+}
+
+// similarity returns 1 minus the edit distance between a and b divided by the longer length
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+
+	return prev[len(b)]
+}
+
+func min(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
+
+// pgnDate is a possibly partial pgn date. Unknown parts are zero.
+type pgnDate struct {
+	year, month, day int
+}
+
+// date returns the Date tag of a game falling back to UTCDate
+func date(tags map[string]string) pgnDate {
+	d := parseDate(tags["Date"])
+	if d.year == 0 {
+		d = parseDate(tags["UTCDate"])
+	}
+	return d
+}
+
+func parseDate(s string) pgnDate {
+	var d pgnDate
+	parts := strings.Split(s, ".")
+	for i, p := range []*int{&d.year, &d.month, &d.day} {
+		if i >= len(parts) {
+			break
+		}
+		n := 0
+		for _, ch := range parts[i] {
+			if ch < '0' || ch > '9' {
+				n = 0
+				break
+			}
+			n = n*10 + int(ch-'0')
+		}
+		*p = n
+	}
+	return d
+}
+
+// closeDates compares the parts of two dates that both have. Complete dates may differ by up
+// to maxDiff.
+func closeDates(a, b pgnDate, maxDiff time.Duration) bool {
+	if a.year == 0 || b.year == 0 {
+		return true
+	}
+	if a.month == 0 || b.month == 0 || a.day == 0 || b.day == 0 {
+		if a.year != b.year {
+			return false
+		}
+		return a.month == 0 || b.month == 0 || a.month == b.month
+	}
+
+	ta := time.Date(a.year, time.Month(a.month), a.day, 0, 0, 0, 0, time.UTC)
+	tb := time.Date(b.year, time.Month(b.month), b.day, 0, 0, 0, 0, time.UTC)
+	diff := ta.Sub(tb)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= maxDiff
+}
+
+func unknown(v string) bool {
+	v = strings.TrimSpace(v)
+	return v == "" || v == "?" || v == "-" || strings.Trim(v, "?.") == ""
+}
@@ -0,0 +1,191 @@
+package dedupe
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/schafer14/go-chess/pgn"
+)
+
+const games = `[Event "Club Championship"]
+[Date "2019.05.04"]
+[White "Carlsen, Magnus"]
+[Black "Caruana, Fabiano"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 1-0
+
+[Event "?"]
+[Date "2019.05.05"]
+[White "Carlsen, M."]
+[Black "Caruana, F"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 { The Spanish } 1-0
+
+[Event "Club Championship"]
+[Date "2019.05.04"]
+[Round "3"]
+[White "Magnus Carlsen"]
+[Black "Fabiano Caruana"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 1-0
+
+[Event "Club Championship"]
+[Date "2019.05.04"]
+[White "Carlsen, Magnus"]
+[Black "Nakamura, Hikaru"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 1-0
+
+[Event "Club Championship"]
+[Date "2018.05.04"]
+[White "Carlsen, Magnus"]
+[Black "Caruana, Fabiano"]
+[Result "1-0"]
+
+1. e4 e5 2. Nf3 Nc6 3. Bb5 1-0
+
+[Event "Club Championship"]
+[Date "2019.05.04"]
+[White "Carlsen, Magnus"]
+[Black "Caruana, Fabiano"]
+[Result "1-0"]
+
+1. d4 d5 1-0
+`
+
+func parse(t *testing.T) []pgn.Game {
+	parsed, err := pgn.Parse(strings.NewReader(games))
+	if err != nil {
+		t.Fatalf("Could not parse test games: %v", err)
+	}
+	return parsed
+}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		want   []Group
+	}{
+		{
+			name:   "Keep first",
+			policy: KeepFirst,
+			want:   []Group{{Kept: 0, Duplicates: []int{1, 2}}},
+		},
+		{
+			name:   "Keep most complete",
+			policy: KeepMostComplete,
+			want:   []Group{{Kept: 2, Duplicates: []int{0, 1}}},
+		},
+		{
+			name:   "Keep most annotated",
+			policy: KeepMostAnnotated,
+			want:   []Group{{Kept: 1, Duplicates: []int{0, 2}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions
+			opts.Policy = tt.policy
+			got := Find(parse(t), opts)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Find() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	kept, groups := Remove(parse(t), DefaultOptions)
+	if len(groups) != 1 {
+		t.Errorf("Remove() found %v groups, want 1", len(groups))
+	}
+	if len(kept) != 4 {
+		t.Fatalf("Remove() kept %v games, want 4", len(kept))
+	}
+	if kept[0].Tags["Round"] != "3" {
+		t.Errorf("Remove() kept the wrong copy: %v", kept[0].Tags)
+	}
+}
+
+func TestSame(t *testing.T) {
+	game := func(round, moves string) pgn.Game {
+		g := pgn.Game{Tags: map[string]string{"Round": round, "White": "A", "Black": "BYE", "Result": "1-0"}}
+		if moves != "" {
+			g.Moves = []pgn.Move{{Move: moves}}
+		}
+		return g
+	}
+
+	tests := []struct {
+		name string
+		a, b pgn.Game
+		want bool
+	}{
+		{"same round", game("1", "e4"), game("1", "e4"), true},
+		{"unknown round", game("1", "e4"), game("?", "e4"), true},
+		{"different rounds", game("1", "e4"), game("2", "e4"), false},
+		{"no moves", game("1", ""), game("1", ""), false},
+		{"no moves in different rounds", game("1", ""), game("2", ""), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Same(tt.a, tt.b, DefaultOptions); got != tt.want {
+				t.Errorf("Same() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSamePlayer(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"Carlsen, Magnus", "Magnus Carlsen", true},
+		{"Carlsen, Magnus", "Carlsen, M.", true},
+		{"Carlsen,M", "Carlsen, Magnus", true},
+		{"Carlsen, Magnus", "?", true},
+		{"Kasparov, Garry", "Kasparov, Gary", true},
+		{"Carlsen, Magnus", "Carlsen, Henrik", false},
+		{"Carlsen, Magnus", "M. C.", false},
+		{"Caruana, Fabiano", "Nakamura, Hikaru", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			if got := SamePlayer(tt.a, tt.b, DefaultOptions.NameSimilarity); got != tt.want {
+				t.Errorf("SamePlayer(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSameDates(t *testing.T) {
+	base := pgn.Game{Tags: map[string]string{"Date": "2019.05.04"}, Moves: []pgn.Move{{Move: "e4"}}}
+
+	tests := []struct {
+		date string
+		want bool
+	}{
+		{"2019.05.04", true},
+		{"2019.05.05", true},
+		{"2019.05.06", false},
+		{"2019.05.??", true},
+		{"2019.??.??", true},
+		{"2018.??.??", false},
+		{"????.??.??", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			other := pgn.Game{Tags: map[string]string{"Date": tt.date}, Moves: []pgn.Move{{Move: "e4+"}}}
+			if got := Same(base, other, DefaultOptions); got != tt.want {
+				t.Errorf("Same() with dates 2019.05.04 and %v = %v, want %v", tt.date, got, tt.want)
+			}
+		})
+	}
+}
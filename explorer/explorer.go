@@ -0,0 +1,288 @@
+// Package explorer builds opening trees from collections of games.
+//
+// A tree records, for each sequence of moves from the standard start position, which moves were
+// played next along with their results, ratings and when they were last played. Positions are
+// identified by the moves that lead to them, so transpositions are counted separately.
+package explorer
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/schafer14/go-chess/pgn"
+)
+
+// Stats are the aggregated results of the games that reached a node
+type Stats struct {
+	Games     int
+	WhiteWins int
+	Draws     int
+	BlackWins int
+	// RatedGames is the number of games where both players have an Elo rating. Only these games
+	// count towards AverageElo and Performance.
+	RatedGames int
+	// MoverElo and OpponentElo are the summed ratings of the player who made the move leading to
+	// the node and of their opponent.
+	MoverElo    int64
+	OpponentElo int64
+	// MoverScore is the summed score of the player who made the move in rated games
+	MoverScore float64
+	// LastPlayed is the latest date of a game reaching this node in PGN format (YYYY.MM.DD)
+	LastPlayed string
+}
+
+// WhitePercent returns the percentage of games won by white
+func (s Stats) WhitePercent() float64 {
+	return percent(s.WhiteWins, s.Games)
+}
+
+// DrawPercent returns the percentage of games drawn
+func (s Stats) DrawPercent() float64 {
+	return percent(s.Draws, s.Games)
+}
+
+// BlackPercent returns the percentage of games won by black
+func (s Stats) BlackPercent() float64 {
+	return percent(s.BlackWins, s.Games)
+}
+
+// AverageElo returns the average rating of the players who made the move, or zero when there
+// are no rated games.
+func (s Stats) AverageElo() int {
+	if s.RatedGames == 0 {
+		return 0
+	}
+	return int(s.MoverElo / int64(s.RatedGames))
+}
+
+// Performance returns the performance rating of the players who made the move using the linear
+// approximation: average opponent rating + 400 * (wins - losses) / games.
+func (s Stats) Performance() int {
+	if s.RatedGames == 0 {
+		return 0
+	}
+	n := float64(s.RatedGames)
+	avgOpponent := float64(s.OpponentElo) / n
+	return int(avgOpponent + 400*(2*s.MoverScore-n)/n)
+}
+
+func (s *Stats) merge(other Stats) {
+	s.Games += other.Games
+	s.WhiteWins += other.WhiteWins
+	s.Draws += other.Draws
+	s.BlackWins += other.BlackWins
+	s.RatedGames += other.RatedGames
+	s.MoverElo += other.MoverElo
+	s.OpponentElo += other.OpponentElo
+	s.MoverScore += other.MoverScore
+	if other.LastPlayed > s.LastPlayed {
+		s.LastPlayed = other.LastPlayed
+	}
+}
+
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(n) / float64(total)
+}
+
+// Node is a position in the tree reached by a sequence of moves
+type Node struct {
+	Stats
+	// Children are the moves played from this position keyed by SAN without check or mate symbols
+	Children map[string]*Node `json:",omitempty"`
+}
+
+// MoveStats are the statistics of a single move played from a position
+type MoveStats struct {
+	Move string
+	Stats
+}
+
+// Moves returns the moves played from the node, most played first
+func (n *Node) Moves() []MoveStats {
+	var moves []MoveStats
+	for move, child := range n.Children {
+		moves = append(moves, MoveStats{Move: move, Stats: child.Stats})
+	}
+
+	sort.Slice(moves, func(i, j int) bool {
+		if moves[i].Games != moves[j].Games {
+			return moves[i].Games > moves[j].Games
+		}
+		return moves[i].Move < moves[j].Move
+	})
+
+	return moves
+}
+
+func (n *Node) child(move string) *Node {
+	if n.Children == nil {
+		n.Children = make(map[string]*Node)
+	}
+	c, ok := n.Children[move]
+	if !ok {
+		c = &Node{}
+		n.Children[move] = c
+	}
+	return c
+}
+
+func (n *Node) merge(other *Node) {
+	n.Stats.merge(other.Stats)
+	for move, c := range other.Children {
+		n.child(move).merge(c)
+	}
+}
+
+// Tree is an opening tree
+type Tree struct {
+	// MaxPly limits how deep into each game moves are recorded. Zero means no limit.
+	MaxPly int
+	Root   *Node
+}
+
+// New returns an empty tree recording at most maxPly half moves of each game
+func New(maxPly int) *Tree {
+	return &Tree{MaxPly: maxPly, Root: &Node{}}
+}
+
+// Add records a game in the tree. Games without a decisive or drawn result, and games that
+// don't start from the standard position (FEN tag), are skipped. Add reports whether the game
+// was recorded.
+func (t *Tree) Add(game pgn.Game) bool {
+	var white, black float64
+	switch game.Tags["Result"] {
+	case "1-0":
+		white = 1
+	case "0-1":
+		black = 1
+	case "1/2-1/2":
+		white, black = 0.5, 0.5
+	default:
+		return false
+	}
+	if _, ok := game.Tags["FEN"]; ok {
+		return false
+	}
+
+	whiteElo, whiteRated := elo(game.Tags["WhiteElo"])
+	blackElo, blackRated := elo(game.Tags["BlackElo"])
+	rated := whiteRated && blackRated
+	date := gameDate(game.Tags)
+
+	record := func(n *Node, whiteMoved bool) {
+		n.Games++
+		switch {
+		case white == 1:
+			n.WhiteWins++
+		case black == 1:
+			n.BlackWins++
+		default:
+			n.Draws++
+		}
+		if rated {
+			n.RatedGames++
+			if whiteMoved {
+				n.MoverElo += whiteElo
+				n.OpponentElo += blackElo
+				n.MoverScore += white
+			} else {
+				n.MoverElo += blackElo
+				n.OpponentElo += whiteElo
+				n.MoverScore += black
+			}
+		}
+		if date > n.LastPlayed {
+			n.LastPlayed = date
+		}
+	}
+
+	// The root is the position before white's first move, so its mover is black
+	node := t.Root
+	record(node, false)
+	for ply, move := range game.Moves {
+		if t.MaxPly > 0 && ply >= t.MaxPly {
+			break
+		}
+		node = node.child(normalize(move.Move))
+		record(node, ply%2 == 0)
+	}
+
+	return true
+}
+
+// AddAll records a list of games in the tree and returns how many were recorded
+func (t *Tree) AddAll(games []pgn.Game) int {
+	var n int
+	for _, g := range games {
+		if t.Add(g) {
+			n++
+		}
+	}
+	return n
+}
+
+// Lookup returns the node reached by playing moves from the start position. Moves are given in
+// SAN and check or mate symbols are ignored.
+func (t *Tree) Lookup(moves []string) (*Node, bool) {
+	node := t.Root
+	for _, m := range moves {
+		c, ok := node.Children[normalize(m)]
+		if !ok {
+			return nil, false
+		}
+		node = c
+	}
+	return node, true
+}
+
+// Merge adds all the games recorded in other to t
+func (t *Tree) Merge(other *Tree) {
+	t.Root.merge(other.Root)
+}
+
+// Save writes the tree to w so it can be read back with Load
+func (t *Tree) Save(w io.Writer) error {
+	return json.NewEncoder(w).Encode(t)
+}
+
+// Load reads a tree written by Save
+func Load(r io.Reader) (*Tree, error) {
+	t := New(0)
+	if err := json.NewDecoder(r).Decode(t); err != nil {
+		return nil, err
+	}
+	if t.Root == nil {
+		t.Root = &Node{}
+	}
+	return t, nil
+}
+
+// normalize strips check and mate symbols so Qh5+ and Qh5 are the same move
+func normalize(move string) string {
+	return strings.TrimRight(move, "+#")
+}
+
+func elo(tag string) (int64, bool) {
+	n, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
+
+// gameDate returns the Date or UTCDate tag if it is a complete date
+func gameDate(tags map[string]string) string {
+	for _, key := range []string{"Date", "UTCDate"} {
+		d := tags[key]
+		if len(d) == 10 && !strings.Contains(d, "?") {
+			return d
+		}
+	}
+	return ""
+}
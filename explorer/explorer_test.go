@@ -0,0 +1,142 @@
+package explorer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/schafer14/go-chess/pgn"
+)
+
+const games = `[Event "A"]
+[Date "2013.01.01"]
+[Result "1-0"]
+[WhiteElo "1600"]
+[BlackElo "1400"]
+
+1. e4 c5 2. Nf3 d6 1-0
+
+[Event "B"]
+[Date "2013.02.01"]
+[Result "0-1"]
+[WhiteElo "1500"]
+[BlackElo "1700"]
+
+1. e4 e5 2. Qh5+ Ke7 0-1
+
+[Event "C"]
+[Date "????.??.??"]
+[Result "1/2-1/2"]
+
+1. d4 d5 1/2-1/2
+
+[Event "D"]
+[Date "2013.03.01"]
+[Result "*"]
+
+1. e4 c5 *
+`
+
+func buildTree(t *testing.T, maxPly int) *Tree {
+	parsed, err := pgn.Parse(strings.NewReader(games))
+	if err != nil {
+		t.Fatalf("Could not parse test games: %v", err)
+	}
+
+	tree := New(maxPly)
+	if n := tree.AddAll(parsed); n != 3 {
+		t.Fatalf("AddAll() recorded %v games, want 3", n)
+	}
+	return tree
+}
+
+func TestTree(t *testing.T) {
+	tree := buildTree(t, 0)
+
+	if tree.Root.Games != 3 {
+		t.Errorf("Root.Games = %v, want 3", tree.Root.Games)
+	}
+
+	moves := tree.Root.Moves()
+	if len(moves) != 2 || moves[0].Move != "e4" || moves[1].Move != "d4" {
+		t.Fatalf("Root.Moves() = %+v, want e4 then d4", moves)
+	}
+
+	e4 := moves[0]
+	if e4.Games != 2 || e4.WhiteWins != 1 || e4.BlackWins != 1 || e4.Draws != 0 {
+		t.Errorf("e4 stats = %+v", e4.Stats)
+	}
+	if e4.WhitePercent() != 50 {
+		t.Errorf("e4 WhitePercent() = %v, want 50", e4.WhitePercent())
+	}
+	if e4.AverageElo() != 1550 {
+		t.Errorf("e4 AverageElo() = %v, want 1550", e4.AverageElo())
+	}
+	// Opponents average 1550 and white scored 1/2
+	if e4.Performance() != 1550 {
+		t.Errorf("e4 Performance() = %v, want 1550", e4.Performance())
+	}
+	if e4.LastPlayed != "2013.02.01" {
+		t.Errorf("e4 LastPlayed = %v, want 2013.02.01", e4.LastPlayed)
+	}
+
+	d4 := moves[1]
+	if d4.RatedGames != 0 || d4.AverageElo() != 0 || d4.LastPlayed != "" {
+		t.Errorf("d4 stats = %+v", d4.Stats)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tree := buildTree(t, 0)
+
+	node, ok := tree.Lookup([]string{"e4", "e5", "Qh5"})
+	if !ok {
+		t.Fatalf("Lookup() could not find 1. e4 e5 2. Qh5")
+	}
+	moves := node.Moves()
+	if len(moves) != 1 || moves[0].Move != "Ke7" {
+		t.Fatalf("Moves() = %+v, want Ke7", moves)
+	}
+	// Black made the move and won against a 1500
+	if moves[0].AverageElo() != 1700 || moves[0].Performance() != 1900 {
+		t.Errorf("Ke7 AverageElo() = %v Performance() = %v, want 1700 and 1900", moves[0].AverageElo(), moves[0].Performance())
+	}
+
+	if _, ok := tree.Lookup([]string{"e4", "e6"}); ok {
+		t.Errorf("Lookup() found a position that was never played")
+	}
+}
+
+func TestMaxPly(t *testing.T) {
+	tree := buildTree(t, 2)
+
+	if _, ok := tree.Lookup([]string{"e4", "c5", "Nf3"}); ok {
+		t.Errorf("Lookup() found a move past the maximum ply")
+	}
+	if _, ok := tree.Lookup([]string{"e4", "c5"}); !ok {
+		t.Errorf("Lookup() could not find a move within the maximum ply")
+	}
+}
+
+func TestMergeAndSave(t *testing.T) {
+	tree := buildTree(t, 0)
+	other := buildTree(t, 0)
+	tree.Merge(other)
+
+	var buf bytes.Buffer
+	if err := tree.Save(&buf); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	node, ok := loaded.Lookup([]string{"e4"})
+	if !ok {
+		t.Fatalf("Lookup() could not find e4 in a loaded tree")
+	}
+	if node.Games != 4 || node.RatedGames != 4 || node.LastPlayed != "2013.02.01" {
+		t.Errorf("merged e4 stats = %+v", node.Stats)
+	}
+}
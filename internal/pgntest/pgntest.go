@@ -0,0 +1,37 @@
+// Package pgntest builds games for tests of the packages that work on collections of games.
+package pgntest
+
+import "github.com/schafer14/go-chess/pgn"
+
+// Game returns a game without moves between white and black. Blank rounds and dates are
+// written as unknown.
+func Game(round, date, white, black, result string) pgn.Game {
+	if round == "" {
+		round = "?"
+	}
+	if date == "" {
+		date = "????.??.??"
+	}
+	return pgn.Game{Tags: map[string]string{
+		"Event":  "Test",
+		"Site":   "?",
+		"Date":   date,
+		"Round":  round,
+		"White":  white,
+		"Black":  black,
+		"Result": result,
+	}}
+}
+
+// Rated sets the WhiteElo and BlackElo tags of games from a map of player ratings
+func Rated(games []pgn.Game, ratings map[string]string) []pgn.Game {
+	for _, g := range games {
+		if r, ok := ratings[g.Tags["White"]]; ok {
+			g.Tags["WhiteElo"] = r
+		}
+		if r, ok := ratings[g.Tags["Black"]]; ok {
+			g.Tags["BlackElo"] = r
+		}
+	}
+	return games
+}
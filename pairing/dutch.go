@@ -0,0 +1,225 @@
+package pairing
+
+import (
+	"sort"
+
+	"github.com/schafer14/go-chess/tournament"
+)
+
+// maxSteps bounds the search so impossible rounds fail instead of running for ever
+const maxSteps = 1000000
+
+type dutch struct {
+	h     []history
+	steps int
+}
+
+// Dutch pairs the next round of a tournament. Boards are ordered by the scores of their players
+// and a bye, if any, is last.
+func Dutch(t *tournament.Tournament, opts Options) ([]Pairing, error) {
+	d := &dutch{}
+	for _, p := range t.Players {
+		d.h = append(d.h, newHistory(t, p))
+	}
+
+	absent := make(map[int]bool)
+	for _, i := range opts.Absent {
+		absent[i] = true
+	}
+	var players []int
+	for i := range t.Players {
+		if !absent[i] {
+			players = append(players, i)
+		}
+	}
+	d.rank(players)
+
+	initial := opts.InitialColour
+	if initial == tournament.NoColour {
+		initial = tournament.White
+	}
+
+	if len(players)%2 == 0 {
+		pairs, ok := d.pairBrackets(scoreGroups(players, d.h), nil)
+		if !ok {
+			return nil, ErrNoPairing
+		}
+		return d.pairings(pairs, tournament.Bye, initial), nil
+	}
+
+	// The bye goes to the lowest ranked player who hasn't had one and leaves a valid pairing
+	for i := len(players) - 1; i >= 0 && d.steps < maxSteps; i-- {
+		bye := players[i]
+		if d.h[bye].hadBye {
+			continue
+		}
+		rest := append(append([]int(nil), players[:i]...), players[i+1:]...)
+		if pairs, ok := d.pairBrackets(scoreGroups(rest, d.h), nil); ok {
+			return d.pairings(pairs, bye, initial), nil
+		}
+	}
+	return nil, ErrNoPairing
+}
+
+// rank sorts players by score and then pairing number
+func (d *dutch) rank(players []int) {
+	sort.SliceStable(players, func(i, j int) bool {
+		a, b := players[i], players[j]
+		if d.h[a].score != d.h[b].score {
+			return d.h[a].score > d.h[b].score
+		}
+		return a < b
+	})
+}
+
+// scoreGroups splits ranked players into groups with the same score
+func scoreGroups(players []int, h []history) [][]int {
+	var groups [][]int
+	for i, p := range players {
+		if i == 0 || h[p].score != h[players[i-1]].score {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], p)
+	}
+	return groups
+}
+
+// pairBrackets pairs the score groups from the top down. floaters are the players moved down
+// from the bracket above.
+func (d *dutch) pairBrackets(groups [][]int, floaters []int) ([][2]int, bool) {
+	if len(groups) == 0 {
+		return nil, len(floaters) == 0
+	}
+
+	bracket := append(append([]int(nil), floaters...), groups[0]...)
+	last := len(groups) == 1
+
+	// Pair as many players as possible and float the rest
+	for pairs := len(bracket) / 2; pairs >= 0; pairs-- {
+		if last && pairs*2 != len(bracket) {
+			return nil, false
+		}
+
+		// Players who floated down last round are only floated again when there is no other way
+		for _, repeatFloats := range []bool{false, true} {
+			var result [][2]int
+			ok := d.pairBracket(bracket, pairs, repeatFloats, func(paired [][2]int, down []int) bool {
+				rest, ok := d.pairBrackets(groups[1:], down)
+				if ok {
+					result = append(append([][2]int(nil), paired...), rest...)
+				}
+				return ok
+			})
+			if ok {
+				return result, true
+			}
+			if d.steps >= maxSteps {
+				return nil, false
+			}
+			if len(bracket) == 2*pairs {
+				// Nobody floats so the second try would be the same
+				break
+			}
+		}
+	}
+	return nil, false
+}
+
+// pairBracket tries the pairings of a bracket with the given number of pairs in Dutch order
+// until next accepts one. The highest unpaired player is paired with the S2 players in order,
+// then with the S1 players, before being floated. Players who floated down last round are only
+// floated when repeatFloats is set.
+func (d *dutch) pairBracket(bracket []int, pairs int, repeatFloats bool, next func(paired [][2]int, down []int) bool) bool {
+	// state of each player in the bracket
+	const (
+		free = iota
+		paired
+		floated
+	)
+	state := make([]int, len(bracket))
+	floats := len(bracket) - 2*pairs
+	var result [][2]int
+
+	var search func() bool
+	search = func() bool {
+		d.steps++
+		if d.steps >= maxSteps {
+			return false
+		}
+
+		x := -1
+		for i := range bracket {
+			if state[i] == free {
+				x = i
+				break
+			}
+		}
+		if x < 0 || len(result) == pairs {
+			var down []int
+			for i := range bracket {
+				if state[i] != paired {
+					if !repeatFloats && d.h[bracket[i]].lastFloat() == floatDown {
+						return false
+					}
+					down = append(down, bracket[i])
+				}
+			}
+			return next(result, down)
+		}
+
+		var candidates []int
+		for _, inS2 := range []bool{true, false} {
+			for i := x + 1; i < len(bracket); i++ {
+				if state[i] == free && (i >= pairs) == inS2 {
+					candidates = append(candidates, i)
+				}
+			}
+		}
+		hx := d.h[bracket[x]]
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return pairingCost(hx, d.h[bracket[candidates[i]]]) < pairingCost(hx, d.h[bracket[candidates[j]]])
+		})
+
+		state[x] = paired
+		for _, c := range candidates {
+			if !compatible(hx, d.h[bracket[c]], bracket[x], bracket[c]) {
+				continue
+			}
+			state[c] = paired
+			result = append(result, [2]int{bracket[x], bracket[c]})
+			if search() {
+				return true
+			}
+			result = result[:len(result)-1]
+			state[c] = free
+		}
+
+		if floats > 0 && (repeatFloats || hx.lastFloat() != floatDown) {
+			floats--
+			state[x] = floated
+			if search() {
+				return true
+			}
+			floats++
+		}
+		state[x] = free
+		return false
+	}
+
+	return search()
+}
+
+// pairings orders the boards and allocates colours
+func (d *dutch) pairings(pairs [][2]int, bye int, initial tournament.Colour) []Pairing {
+	boardOrder(pairs, d.h)
+
+	var pairings []Pairing
+	for _, p := range pairs {
+		white, black := allocate(d.h[p[0]], d.h[p[1]], p[0], p[1], initial)
+		pairings = append(pairings, Pairing{White: white, Black: black})
+	}
+	if bye != tournament.Bye {
+		pairings = append(pairings, Pairing{White: bye, Black: tournament.Bye})
+	}
+	return pairings
+}
@@ -0,0 +1,319 @@
+// Package pairing pairs the next round of a Swiss tournament with the FIDE Dutch system.
+//
+// Players are identified by their index in tournament.Tournament.Players, which is also their
+// pairing number. The games already played, read from pgn with tournament.FromGames, give each
+// player's score, colour history, previous opponents and byes.
+//
+// The absolute criteria are always kept: no two players meet twice, nobody receives a second
+// bye, and nobody gets a colour difference above two or the same colour three times in a row.
+// Within those, brackets are paired by score with S1 against S2 in transposition order, then
+// exchanges, floating players down when a bracket can't be paired completely and preferring
+// pairings that meet the players' colour preferences. Players who floated down in the last round,
+// including by receiving the bye, are only floated down again when no one else can be, and
+// players who floated up in the last round are paired with floaters last. It is a depth first search rather than
+// the full weighted optimisation of the FIDE rules, so very constrained late rounds may float
+// different players than a certified pairing program would.
+package pairing
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/schafer14/go-chess/pgn"
+	"github.com/schafer14/go-chess/tournament"
+)
+
+// ErrNoPairing is returned when the players can't be paired without breaking an absolute
+// criterion
+var ErrNoPairing = errors.New("no pairing satisfies the absolute criteria")
+
+// Pairing is a board of a round
+type Pairing struct {
+	White int
+	// Black is tournament.Bye when White receives the bye
+	Black int
+}
+
+// Options configure the pairing of a round
+type Options struct {
+	// Absent players are left out of the round
+	Absent []int
+	// InitialColour is given to odd numbered players when neither player has a colour
+	// preference, as in the first round. NoColour means White.
+	InitialColour tournament.Colour
+}
+
+// strength of a colour preference
+const (
+	noPreference = iota
+	mild
+	strong
+	absolute
+)
+
+type preference struct {
+	colour   tournament.Colour
+	strength int
+}
+
+// Directions a player floated in a round
+const (
+	noFloat = iota
+	floatDown
+	floatUp
+)
+
+// history is what the pairing needs to know about a player's previous rounds
+type history struct {
+	score float64
+	// diff is the number of whites minus the number of blacks
+	diff    int
+	colours []tournament.Colour
+	played  map[int]bool
+	hadBye  bool
+	// floats is the direction the player floated in each round, starting with round 1
+	floats []int
+}
+
+func newHistory(t *tournament.Tournament, p *tournament.Player) history {
+	h := history{score: p.Score(), played: make(map[int]bool), floats: make([]int, t.Rounds)}
+	for _, g := range p.Games {
+		if g.Round >= 1 && g.Round <= t.Rounds {
+			h.floats[g.Round-1] = floated(t, p, g)
+		}
+		switch {
+		case g.Opponent == tournament.Bye:
+			h.hadBye = true
+		case g.Colour == tournament.White:
+			h.diff++
+			h.colours = append(h.colours, g.Colour)
+			h.played[g.Opponent] = true
+		case g.Colour == tournament.Black:
+			h.diff--
+			h.colours = append(h.colours, g.Colour)
+			h.played[g.Opponent] = true
+		}
+	}
+	return h
+}
+
+// floated returns the direction a player floated in a game. A full point bye counts as a
+// float down.
+func floated(t *tournament.Tournament, p *tournament.Player, g tournament.Game) int {
+	if g.Opponent == tournament.Bye {
+		if g.Score == 1 {
+			return floatDown
+		}
+		return noFloat
+	}
+	own, opponent := p.ScoreBefore(g.Round), t.Players[g.Opponent].ScoreBefore(g.Round)
+	switch {
+	case own > opponent:
+		return floatDown
+	case own < opponent:
+		return floatUp
+	}
+	return noFloat
+}
+
+// lastFloat returns the direction the player floated in the last round
+func (h history) lastFloat() int {
+	if len(h.floats) == 0 {
+		return noFloat
+	}
+	return h.floats[len(h.floats)-1]
+}
+
+func opposite(c tournament.Colour) tournament.Colour {
+	if c == tournament.White {
+		return tournament.Black
+	}
+	return tournament.White
+}
+
+func (h history) last() tournament.Colour {
+	if len(h.colours) == 0 {
+		return tournament.NoColour
+	}
+	return h.colours[len(h.colours)-1]
+}
+
+func (h history) preference() preference {
+	n := len(h.colours)
+	switch {
+	case n == 0:
+		return preference{}
+	case h.diff > 1:
+		return preference{tournament.Black, absolute}
+	case h.diff < -1:
+		return preference{tournament.White, absolute}
+	case n >= 2 && h.colours[n-1] == h.colours[n-2]:
+		return preference{opposite(h.colours[n-1]), absolute}
+	case h.diff == 1:
+		return preference{tournament.Black, strong}
+	case h.diff == -1:
+		return preference{tournament.White, strong}
+	default:
+		return preference{opposite(h.last()), mild}
+	}
+}
+
+// compatible reports whether two players may meet
+func compatible(a, b history, ia, ib int) bool {
+	if a.played[ib] || b.played[ia] {
+		return false
+	}
+	pa, pb := a.preference(), b.preference()
+	return !(pa.strength == absolute && pb.strength == absolute && pa.colour == pb.colour)
+}
+
+// colourCost is how badly a pairing misses the players' colour preferences
+func colourCost(a, b history) int {
+	pa, pb := a.preference(), b.preference()
+	if pa.colour == tournament.NoColour || pb.colour == tournament.NoColour || pa.colour != pb.colour {
+		return 0
+	}
+	if pa.strength >= strong && pb.strength >= strong {
+		return 2
+	}
+	return 1
+}
+
+// pairingCost orders the opponents of a: first by how badly they miss the colour preferences,
+// then putting a floater's opponents who floated up last round at the end
+func pairingCost(a, b history) int {
+	cost := 2 * colourCost(a, b)
+	if a.score > b.score && b.lastFloat() == floatUp {
+		cost++
+	}
+	return cost
+}
+
+// allocate gives colours to two paired players where a is ranked higher than b and returns
+// the player with white first
+func allocate(a, b history, ia, ib int, initial tournament.Colour) (int, int) {
+	pa, pb := a.preference(), b.preference()
+
+	var white tournament.Colour
+	switch {
+	case pa.colour == tournament.NoColour && pb.colour == tournament.NoColour:
+		// The higher ranked player gets the initial colour if their pairing number is odd
+		white = initial
+		if (ia+1)%2 == 0 {
+			white = opposite(initial)
+		}
+	case pb.colour == tournament.NoColour:
+		white = pa.colour
+	case pa.colour == tournament.NoColour:
+		white = opposite(pb.colour)
+	case pa.colour != pb.colour:
+		white = pa.colour
+	case pa.strength != pb.strength:
+		if pa.strength > pb.strength {
+			white = pa.colour
+		} else {
+			white = opposite(pb.colour)
+		}
+	case abs(a.diff) != abs(b.diff):
+		if abs(a.diff) > abs(b.diff) {
+			white = pa.colour
+		} else {
+			white = opposite(pb.colour)
+		}
+	default:
+		// Alternate the colours from the last round the players had different colours
+		white = pa.colour
+		for i, j := len(a.colours)-1, len(b.colours)-1; i >= 0 && j >= 0; i, j = i-1, j-1 {
+			if a.colours[i] != b.colours[j] {
+				white = opposite(a.colours[i])
+				break
+			}
+		}
+	}
+
+	if white == tournament.White {
+		return ia, ib
+	}
+	return ib, ia
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Games returns pgn stubs for the pairings of a round with the Event, Round, White, Black and
+// Elo tags filled in and an unfinished result. Rounds are numbered round.board. A bye is a 1-0
+// game against BYE so it is read back by tournament.FromGames.
+func Games(t *tournament.Tournament, round int, pairings []Pairing) []pgn.Game {
+	var games []pgn.Game
+	for board, p := range pairings {
+		tags := map[string]string{
+			"Event":  t.Event,
+			"Site":   "?",
+			"Date":   "????.??.??",
+			"Round":  strconv.Itoa(round) + "." + strconv.Itoa(board+1),
+			"White":  t.Players[p.White].Name,
+			"Result": "*",
+		}
+		if r := t.Players[p.White].Rating; r > 0 {
+			tags["WhiteElo"] = strconv.Itoa(r)
+		}
+
+		if p.Black == tournament.Bye {
+			tags["Black"] = "BYE"
+			tags["Result"] = "1-0"
+		} else {
+			tags["Black"] = t.Players[p.Black].Name
+			if r := t.Players[p.Black].Rating; r > 0 {
+				tags["BlackElo"] = strconv.Itoa(r)
+			}
+		}
+
+		games = append(games, pgn.Game{Tags: tags})
+	}
+	return games
+}
+
+// String returns the pairing as "white - black" pairing numbers
+func (p Pairing) String() string {
+	if p.Black == tournament.Bye {
+		return fmt.Sprintf("%d - bye", p.White+1)
+	}
+	return fmt.Sprintf("%d - %d", p.White+1, p.Black+1)
+}
+
+// boardOrder sorts pairs by the higher score, then the sum of the scores, then the rank of the
+// higher ranked player
+func boardOrder(pairs [][2]int, h []history) {
+	sort.SliceStable(pairs, func(i, j int) bool {
+		a, b := pairs[i], pairs[j]
+		maxA, maxB := maxScore(h, a), maxScore(h, b)
+		if maxA != maxB {
+			return maxA > maxB
+		}
+		sumA, sumB := h[a[0]].score+h[a[1]].score, h[b[0]].score+h[b[1]].score
+		if sumA != sumB {
+			return sumA > sumB
+		}
+		return minInt(a[0], a[1]) < minInt(b[0], b[1])
+	})
+}
+
+func maxScore(h []history, pair [2]int) float64 {
+	if h[pair[0]].score > h[pair[1]].score {
+		return h[pair[0]].score
+	}
+	return h[pair[1]].score
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
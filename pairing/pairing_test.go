@@ -0,0 +1,172 @@
+package pairing
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/schafer14/go-chess/internal/pgntest"
+	"github.com/schafer14/go-chess/pgn"
+	"github.com/schafer14/go-chess/tournament"
+)
+
+var ratings = map[string]string{"P1": "2000", "P2": "1900", "P3": "1800", "P4": "1700", "P5": "1600", "P6": "1500", "P7": "1400", "P8": "1300"}
+
+// entrants is a tournament that n players have entered before the first round
+func entrants(n int) *tournament.Tournament {
+	t := &tournament.Tournament{Event: "Test"}
+	for i := 1; i <= n; i++ {
+		name := "P" + strconv.Itoa(i)
+		rating, _ := strconv.Atoi(ratings[name])
+		t.Players = append(t.Players, &tournament.Player{Name: name, Rating: rating})
+	}
+	return t
+}
+
+// played is a tournament built from the games of its rounds so far
+func played(games ...pgn.Game) *tournament.Tournament {
+	return tournament.FromGames(pgntest.Rated(games, ratings))
+}
+
+func TestDutch(t *testing.T) {
+	tests := []struct {
+		name string
+		t    *tournament.Tournament
+		opts Options
+		want []Pairing
+	}{
+		{
+			"first round",
+			entrants(6),
+			Options{},
+			[]Pairing{{0, 3}, {4, 1}, {2, 5}},
+		},
+		{
+			"first round with black as the initial colour",
+			entrants(4),
+			Options{InitialColour: tournament.Black},
+			[]Pairing{{2, 0}, {1, 3}},
+		},
+		{
+			"first round with a bye",
+			entrants(4),
+			Options{Absent: []int{0}},
+			[]Pairing{{2, 1}, {3, tournament.Bye}},
+		},
+		{
+			"second round floats players who have met",
+			played(
+				pgntest.Game("1", "", "P1", "P4", "1-0"),
+				pgntest.Game("1", "", "P5", "P2", "1-0"),
+				pgntest.Game("1", "", "P3", "P6", "1/2-1/2"),
+			),
+			Options{},
+			[]Pairing{{4, 0}, {1, 2}, {5, 3}},
+		},
+		{
+			"players who floated down last round don't float again",
+			played(
+				pgntest.Game("1", "", "P1", "P4", "1/2-1/2"),
+				pgntest.Game("1", "", "P5", "P2", "1/2-1/2"),
+				pgntest.Game("1", "", "P3", "P6", "0-1"),
+				pgntest.Game("2", "", "P6", "P5", "0-1"),
+				pgntest.Game("2", "", "P2", "P1", "0-1"),
+				pgntest.Game("2", "", "P4", "P3", "1-0"),
+			),
+			Options{},
+			// P4 floated down to P3 so P1 floats down to P6 instead
+			[]Pairing{{4, 3}, {0, 5}, {2, 1}},
+		},
+		{
+			"no second bye",
+			played(
+				pgntest.Game("1", "", "P1", "P2", "1-0"),
+				pgntest.Game("1", "", "P3", "BYE", "1-0"),
+			),
+			Options{},
+			[]Pairing{{2, 0}, {1, tournament.Bye}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Dutch(tt.t, tt.opts)
+			if err != nil {
+				t.Fatalf("Dutch() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Dutch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDutch_noPairing(t *testing.T) {
+	tr := played(pgntest.Game("1", "", "P1", "P2", "1-0"))
+	if _, err := Dutch(tr, Options{}); err != ErrNoPairing {
+		t.Errorf("Dutch() error = %v, want %v", err, ErrNoPairing)
+	}
+}
+
+func TestDutch_fullTournament(t *testing.T) {
+	// Play seven rounds with eight players, the higher rated player always winning
+	tr := entrants(8)
+	var games []pgn.Game
+	for round := 1; round <= 7; round++ {
+		pairings, err := Dutch(tr, Options{})
+		if err != nil {
+			t.Fatalf("round %v: Dutch() error = %v", round, err)
+		}
+		for i, g := range Games(tr, round, pairings) {
+			p := pairings[i]
+			if p.White < p.Black {
+				g.Tags["Result"] = "1-0"
+			} else {
+				g.Tags["Result"] = "0-1"
+			}
+			games = append(games, g)
+		}
+		tr = tournament.FromGames(games)
+	}
+
+	if !tr.IsRoundRobin() {
+		t.Fatalf("seven rounds with eight players should be a round robin")
+	}
+	for _, p := range tr.Players {
+		var diff int
+		for _, g := range p.Games {
+			if g.Colour == tournament.White {
+				diff++
+			} else {
+				diff--
+			}
+		}
+		if diff > 2 || diff < -2 {
+			t.Errorf("%v has a colour difference of %v", p.Name, diff)
+		}
+	}
+}
+
+func TestGames(t *testing.T) {
+	games := Games(entrants(4), 1, []Pairing{{0, 2}, {3, tournament.Bye}})
+
+	want := map[string]string{
+		"Event":    "Test",
+		"Site":     "?",
+		"Date":     "????.??.??",
+		"Round":    "1.1",
+		"White":    "P1",
+		"Black":    "P3",
+		"WhiteElo": "2000",
+		"BlackElo": "1800",
+		"Result":   "*",
+	}
+	if !reflect.DeepEqual(games[0].Tags, want) {
+		t.Errorf("Games() = %v, want %v", games[0].Tags, want)
+	}
+
+	// The bye is read back as a point for P4
+	back := tournament.FromGames(games)
+	if p := back.Players[len(back.Players)-1]; p.Name != "P4" || p.Score() != 1 {
+		t.Errorf("bye = %v with %v points, want P4 with 1", p.Name, p.Score())
+	}
+}
@@ -0,0 +1,174 @@
+package pgn
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockCommand matches the clock commands embedded in comments such as [%clk 0:03:12]
+var clockCommand = regexp.MustCompile(`\[%(clk|emt|timestamp)\s+([^\]]*?)\s*\]`)
+
+// removed marks where a clock command was taken out of a comment and commandGap matches it
+// along with the whitespace around it
+const removed = "\x00"
+
+var commandGap = regexp.MustCompile(`\s*\x00[\s\x00]*`)
+
+// Comment returns the move's comment as it is written in pgn: the clock commands followed by the
+// annotation
+func (m Move) Comment() string {
+	tokens := clockTokens(m)
+	if m.Annotation != "" {
+		tokens = append(tokens, m.Annotation)
+	}
+	return strings.Join(tokens, " ")
+}
+
+// SetComment sets the move's clock fields from the [%clk], [%emt] and [%timestamp] commands in
+// a comment and its annotation to the rest of the comment. Commands with values that can't be
+// read are left in the annotation.
+func (m *Move) SetComment(comment string) {
+	found := false
+	comment = clockCommand.ReplaceAllStringFunc(comment, func(command string) string {
+		match := clockCommand.FindStringSubmatch(command)
+		switch match[1] {
+		case "clk":
+			if d, err := ParseClock(match[2]); err == nil {
+				m.Clock = &d
+				found = true
+				return removed
+			}
+		case "emt":
+			if d, err := ParseClock(match[2]); err == nil {
+				m.Elapsed = &d
+				found = true
+				return removed
+			}
+		case "timestamp":
+			if n, err := strconv.Atoi(match[2]); err == nil && n >= 0 {
+				d := time.Duration(n) * time.Second / 10
+				m.Timestamp = &d
+				found = true
+				return removed
+			}
+		}
+		return command
+	})
+	if found {
+		comment = closeGaps(comment)
+	}
+	m.Annotation = strings.Trim(comment, " ")
+}
+
+// closeGaps removes the gaps left by clock commands. Gaps between text become a single space,
+// or a line break if there was one, and gaps at the start or end are dropped.
+func closeGaps(comment string) string {
+	var b strings.Builder
+	last := 0
+	for _, gap := range commandGap.FindAllStringIndex(comment, -1) {
+		b.WriteString(comment[last:gap[0]])
+		last = gap[1]
+		if gap[0] == 0 || gap[1] == len(comment) {
+			continue
+		}
+		if strings.Contains(comment[gap[0]:gap[1]], "\n") {
+			b.WriteString("\n")
+		} else {
+			b.WriteString(" ")
+		}
+	}
+	b.WriteString(comment[last:])
+	return b.String()
+}
+
+// commentTokens returns the clock commands and the words of the annotation
+func commentTokens(m Move) []string {
+	return append(clockTokens(m), strings.Fields(m.Annotation)...)
+}
+
+// clockTokens returns the move's clock commands
+func clockTokens(m Move) []string {
+	var tokens []string
+	if m.Clock != nil {
+		tokens = append(tokens, "[%clk "+FormatClock(*m.Clock)+"]")
+	}
+	if m.Elapsed != nil {
+		tokens = append(tokens, "[%emt "+FormatClock(*m.Elapsed)+"]")
+	}
+	if m.Timestamp != nil {
+		tokens = append(tokens, "[%timestamp "+strconv.FormatInt(int64(*m.Timestamp/(time.Second/10)), 10)+"]")
+	}
+	return tokens
+}
+
+// ParseClock parses a clock value of the form H:MM:SS or MM:SS with optional fractions of a
+// second, such as 0:03:12 or 1:59.9. A leading minus sign, as FormatClock writes for negative
+// durations, makes the value negative.
+func ParseClock(s string) (time.Duration, error) {
+	body := strings.TrimPrefix(s, "-")
+	parts := strings.Split(body, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid clock %q", s)
+	}
+
+	var d time.Duration
+	for i, part := range parts[:len(parts)-1] {
+		n, err := strconv.Atoi(part)
+		if !digits(part) || err != nil || (i > 0 && n > 59) {
+			return 0, fmt.Errorf("invalid clock %q", s)
+		}
+		d = d*60 + time.Duration(n)
+	}
+
+	// Seconds are digits with an optional fraction, which strconv.ParseFloat is too lenient for
+	whole, frac := parts[len(parts)-1], ""
+	if i := strings.IndexByte(whole, '.'); i >= 0 {
+		whole, frac = whole[:i], whole[i+1:]
+		if !digits(frac) {
+			return 0, fmt.Errorf("invalid clock %q", s)
+		}
+	}
+	seconds, err := strconv.Atoi(whole)
+	if !digits(whole) || err != nil || seconds > 59 {
+		return 0, fmt.Errorf("invalid clock %q", s)
+	}
+	d = d*time.Minute + time.Duration(seconds)*time.Second
+	if frac != "" {
+		if len(frac) > 9 {
+			frac = frac[:9]
+		}
+		nanos, _ := strconv.Atoi(frac + strings.Repeat("0", 9-len(frac)))
+		d += time.Duration(nanos)
+	}
+
+	if body != s {
+		d = -d
+	}
+	return d, nil
+}
+
+// digits reports whether s is a non-empty string of ASCII digits
+func digits(s string) bool {
+	for _, ch := range s {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// FormatClock formats a duration as H:MM:SS, adding fractions of a second when there are any
+func FormatClock(d time.Duration) string {
+	sign := ""
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	s := fmt.Sprintf("%s%d:%02d:%02d", sign, d/time.Hour, d/time.Minute%60, d/time.Second%60)
+	if frac := d % time.Second; frac != 0 {
+		s += "." + strings.TrimRight(fmt.Sprintf("%09d", frac), "0")
+	}
+	return s
+}
@@ -0,0 +1,105 @@
+package pgn
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func duration(d time.Duration) *time.Duration {
+	return &d
+}
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		clock   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"0:03:12", 3*time.Minute + 12*time.Second, false},
+		{"1:30:00", 90 * time.Minute, false},
+		{"0:00:09.8", 9800 * time.Millisecond, false},
+		{"2:05", 2*time.Minute + 5*time.Second, false},
+		{"-0:00:05", -5 * time.Second, false},
+		{"-1:02:03.5", -(time.Hour + 2*time.Minute + 3500*time.Millisecond), false},
+		{"0:-1:00", 0, true},
+		{"--0:00:05", 0, true},
+		{"0:60:00", 0, true},
+		{"0:00:60", 0, true},
+		{"12", 0, true},
+		{"a:00:00", 0, true},
+		{"0:00:NaN", 0, true},
+		{"0:00:1e1", 0, true},
+		{"0:00:0x1p3", 0, true},
+		{"0:00:05.", 0, true},
+		{"0:00:+5", 0, true},
+		{"0:00:.5", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.clock, func(t *testing.T) {
+			got, err := ParseClock(tt.clock)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseClock() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseClock() = %v, want %v", got, tt.want)
+			}
+			if !tt.wantErr && strings.Count(tt.clock, ":") == 2 {
+				if s := FormatClock(got); s != tt.clock {
+					t.Errorf("FormatClock() = %v, want %v", s, tt.clock)
+				}
+			}
+		})
+	}
+}
+
+func TestMove_SetComment(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment string
+		want    Move
+	}{
+		{"lichess", " [%eval 0.17] [%clk 0:03:00] ", Move{Annotation: "[%eval 0.17]", Clock: duration(3 * time.Minute)}},
+		{"chess.com", "[%clk 0:02:59.9][%timestamp 12]", Move{Clock: duration(2*time.Minute + 59900*time.Millisecond), Timestamp: duration(1200 * time.Millisecond)}},
+		{"elapsed and text", "Good move [%emt 0:00:05] here", Move{Annotation: "Good move here", Elapsed: duration(5 * time.Second)}},
+		{"invalid clock is kept", "[%clk soon]", Move{Annotation: "[%clk soon]"}},
+		{"whitespace is kept without commands", " Line one\n\nLine   two ", Move{Annotation: "Line one\n\nLine   two"}},
+		{"line break around a command", "Line one\n[%clk 0:01:00]\nLine two", Move{Annotation: "Line one\nLine two", Clock: duration(time.Minute)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Move
+			got.SetComment(tt.comment)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SetComment() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClockRoundTrip(t *testing.T) {
+	games, err := Parse(strings.NewReader(`[Event "Clocks"]
+
+1. e4 { [%clk 0:03:00] } 1... e5 { Solid [%clk 0:02:58.5] [%emt 0:00:01.5] } 2. Nf3 1-0`))
+	if err != nil {
+		t.Fatalf("Could not parse game: %v", err)
+	}
+
+	m := games[0].Moves[1]
+	if m.Annotation != "Solid" || *m.Clock != 178500*time.Millisecond || *m.Elapsed != 1500*time.Millisecond {
+		t.Errorf("Parse() move = %+v", m)
+	}
+
+	var sb strings.Builder
+	if err := Write(&sb, games); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	got, err := Parse(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatalf("Could not parse written game: %v\n%v", err, sb.String())
+	}
+	if !reflect.DeepEqual(got[0].Moves, games[0].Moves) {
+		t.Errorf("Moves after writing = %+v, want %+v", got[0].Moves, games[0].Moves)
+	}
+}
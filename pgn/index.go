@@ -0,0 +1,372 @@
+package pgn
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+// indexMagic starts every index file and identifies its version
+const indexMagic = "PGNIDX2\n"
+
+// indexPrefix is shared by every version of the index file format. Indexes written by an older
+// version are rebuilt.
+const indexPrefix = "PGNIDX"
+
+// errIndexVersion is returned by readIndex for an index written by another version
+var errIndexVersion = errors.New("pgn index has an unsupported version")
+
+// IndexTags are the tags stored in an index for each game. Only these tags can be used for
+// lookups without reading the games themselves.
+var IndexTags = []string{"Event", "Site", "Date", "Round", "White", "Black", "Result", "WhiteElo", "BlackElo", "ECO"}
+
+// IndexEntry describes where a game is in a pgn file
+type IndexEntry struct {
+	// Offset is the byte offset of the first tag of the game
+	Offset int64
+	// Length is the number of bytes from Offset to the end of the game result
+	Length int64
+	// Tags are the values of the IndexTags that the game has
+	Tags map[string]string
+
+	// checksum is the CRC-32 of the bytes of the game, used to check the pgn file still holds
+	// the games that were indexed
+	checksum uint32
+}
+
+// IndexedFile gives random access to the games in a pgn file using an index file that is built
+// once and then kept up to date as games are appended to the pgn file.
+type IndexedFile struct {
+	file      *os.File
+	indexPath string
+	entries   []IndexEntry
+}
+
+// OpenIndexed opens a pgn file along with its index. If indexPath is empty the index is kept
+// next to the pgn file with an .idx extension. A missing index is built by scanning the whole
+// file, and games appended since the index was written are added to it.
+func OpenIndexed(filePath, indexPath string) (*IndexedFile, error) {
+	if indexPath == "" {
+		indexPath = filePath + ".idx"
+	}
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &IndexedFile{file: file, indexPath: indexPath}
+
+	f.entries, err = readIndex(indexPath)
+	if err != nil && !os.IsNotExist(err) && err != errIndexVersion {
+		file.Close()
+		return nil, err
+	}
+	if err != nil {
+		if err := writeIndexHeader(indexPath); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+
+	if _, err := f.Update(); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Update scans games appended to the pgn file since the index was last updated and adds them
+// to the index. It returns the number of games added. If the pgn file is shorter than what was
+// indexed, or the last indexed game has changed, the index is rebuilt from scratch.
+func (f *IndexedFile) Update() (int, error) {
+	info, err := f.file.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	var start int64
+	changed := false
+	if len(f.entries) > 0 {
+		last := f.entries[len(f.entries)-1]
+		start = last.Offset + last.Length
+		if start <= info.Size() {
+			sum, err := f.checksum(last)
+			if err != nil {
+				return 0, err
+			}
+			changed = sum != last.checksum
+		}
+	}
+
+	if changed || start > info.Size() {
+		f.entries = nil
+		start = 0
+		if err := writeIndexHeader(f.indexPath); err != nil {
+			return 0, err
+		}
+	}
+
+	if _, err := f.file.Seek(start, io.SeekStart); err != nil {
+		return 0, err
+	}
+	entries := scanIndex(bufio.NewReader(f.file), start)
+	if len(entries) == 0 {
+		return 0, nil
+	}
+	for i := range entries {
+		if entries[i].checksum, err = f.checksum(entries[i]); err != nil {
+			return 0, err
+		}
+	}
+
+	index, err := os.OpenFile(f.indexPath, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+	w := bufio.NewWriter(index)
+	for _, e := range entries {
+		writeIndexEntry(w, e)
+	}
+	if err := w.Flush(); err != nil {
+		index.Close()
+		return 0, err
+	}
+	if err := index.Close(); err != nil {
+		return 0, err
+	}
+
+	f.entries = append(f.entries, entries...)
+	return len(entries), nil
+}
+
+// Len returns the number of games in the file
+func (f *IndexedFile) Len() int {
+	return len(f.entries)
+}
+
+// Entry returns the index entry of the i-th game
+func (f *IndexedFile) Entry(i int) IndexEntry {
+	return f.entries[i]
+}
+
+// Game reads and parses the i-th game in the file
+func (f *IndexedFile) Game(i int) (Game, error) {
+	if i < 0 || i >= len(f.entries) {
+		return Game{}, fmt.Errorf("game %v out of range, the file has %v games", i, len(f.entries))
+	}
+	e := f.entries[i]
+
+	var s Scanner
+	s.Init(io.NewSectionReader(f.file, e.Offset, e.Length))
+	p := parser{s}
+
+	return p.parseGame()
+}
+
+// Find returns the indexes of the games whose tag has exactly the given value. The tag must
+// be one of the IndexTags.
+func (f *IndexedFile) Find(tag, value string) []int {
+	return f.FindFunc(func(tags map[string]string) bool {
+		return tags[tag] == value
+	})
+}
+
+// FindFunc returns the indexes of the games whose indexed tags satisfy match
+func (f *IndexedFile) FindFunc(match func(tags map[string]string) bool) []int {
+	var found []int
+	for i, e := range f.entries {
+		if match(e.Tags) {
+			found = append(found, i)
+		}
+	}
+	return found
+}
+
+// Close closes the pgn file
+func (f *IndexedFile) Close() error {
+	return f.file.Close()
+}
+
+// checksum returns the CRC-32 of the bytes of the game at e in the pgn file
+func (f *IndexedFile) checksum(e IndexEntry) (uint32, error) {
+	h := crc32.NewIEEE()
+	if _, err := io.Copy(h, io.NewSectionReader(f.file, e.Offset, e.Length)); err != nil {
+		return 0, err
+	}
+	return h.Sum32(), nil
+}
+
+// scanIndex finds the games in r, which starts at byte offset base of the file. Only tags are
+// parsed, the movetext is skipped up to the result. A game without a result is left out: if a
+// tag starts a line before the result the scan carries on from that tag, and a trailing game is
+// left to be indexed once it has been completely written.
+func scanIndex(r io.Reader, base int64) []IndexEntry {
+	var entries []IndexEntry
+	var s Scanner
+	s.Init(r)
+	p := parser{s}
+
+	for {
+		if p.p.Peek() == eof {
+			break
+		}
+
+		start := int64(p.p.s.Pos().Offset)
+		tags, err := p.parseTags()
+		if err != nil {
+			p.recover(false)
+			continue
+		}
+
+		var tok Token
+		for tok.Tok != Result && tok.Tok != EOF && !p.atTagLine() {
+			tok = p.p.Next()
+		}
+		if tok.Tok == EOF {
+			break
+		}
+		if tok.Tok != Result {
+			continue
+		}
+		end := int64(p.p.s.Pos().Offset)
+
+		entry := IndexEntry{Offset: base + start, Length: end - start, Tags: make(map[string]string)}
+		for _, key := range IndexTags {
+			if v, ok := tags[key]; ok {
+				entry.Tags[key] = v
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// atTagLine reports whether the next token is a '[' at the start of a line, which begins the
+// tags of the next game
+func (p *parser) atTagLine() bool {
+	return p.p.Peek() == '[' && p.p.s.Pos().Column == 1
+}
+
+func writeIndexHeader(indexPath string) error {
+	return ioutil.WriteFile(indexPath, []byte(indexMagic), 0644)
+}
+
+// writeIndexEntry writes an entry as uvarints: offset, length, checksum, number of tags and then
+// each tag key and value as a length followed by the bytes.
+func writeIndexEntry(w *bufio.Writer, e IndexEntry) {
+	var buf [binary.MaxVarintLen64]byte
+	putUvarint := func(v uint64) {
+		n := binary.PutUvarint(buf[:], v)
+		w.Write(buf[:n])
+	}
+	putString := func(s string) {
+		putUvarint(uint64(len(s)))
+		w.WriteString(s)
+	}
+
+	putUvarint(uint64(e.Offset))
+	putUvarint(uint64(e.Length))
+	putUvarint(uint64(e.checksum))
+	// Tags are sorted so index files are reproducible
+	keys := make([]string, 0, len(e.Tags))
+	for key := range e.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	putUvarint(uint64(len(keys)))
+	for _, key := range keys {
+		putString(key)
+		putString(e.Tags[key])
+	}
+}
+
+func readIndex(indexPath string) ([]IndexEntry, error) {
+	file, err := os.Open(indexPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	r := bufio.NewReader(file)
+
+	magic := make([]byte, len(indexMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || !strings.HasPrefix(string(magic), indexPrefix) {
+		return nil, fmt.Errorf("%s is not a pgn index file", indexPath)
+	}
+	if string(magic) != indexMagic {
+		return nil, errIndexVersion
+	}
+
+	readString := func() (string, error) {
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return "", err
+		}
+		b := make([]byte, n)
+		_, err = io.ReadFull(r, b)
+		return string(b), err
+	}
+
+	var entries []IndexEntry
+	for {
+		offset, err := binary.ReadUvarint(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var e IndexEntry
+		e.Offset = int64(offset)
+		length, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, corruptIndex(indexPath, err)
+		}
+		e.Length = int64(length)
+
+		checksum, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, corruptIndex(indexPath, err)
+		}
+		e.checksum = uint32(checksum)
+
+		n, err := binary.ReadUvarint(r)
+		if err != nil {
+			return nil, corruptIndex(indexPath, err)
+		}
+		e.Tags = make(map[string]string, n)
+		for i := uint64(0); i < n; i++ {
+			key, err := readString()
+			if err != nil {
+				return nil, corruptIndex(indexPath, err)
+			}
+			value, err := readString()
+			if err != nil {
+				return nil, corruptIndex(indexPath, err)
+			}
+			e.Tags[key] = value
+		}
+
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+func corruptIndex(indexPath string, err error) error {
+	if err == io.EOF {
+		err = errors.New("unexpected end of file")
+	}
+	return fmt.Errorf("corrupt pgn index %s: %v", indexPath, err)
+}
@@ -0,0 +1,222 @@
+package pgn
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const indexGames = `[Event "First"]
+[White "Alice"]
+[Black "Bob"]
+[Result "1-0"]
+[Opening "Not indexed"]
+
+1. e4 e5 2. Qh5 Nc6 3. Bc4 Nf6 4. Qxf7# 1-0
+
+[Event "Second"]
+[White "Bob"]
+[Black "Alice"]
+[Result "0-1"]
+
+1. f3 e5 2. g4 Qh4# 0-1
+
+[Event "Broken"
+[White "Nobody"]
+
+1. e4 *
+
+[Event "Variation"]
+[White "Carol"]
+[Black "Alice"]
+[Result "1/2-1/2"]
+
+1. d4 (1. e4 { best by test }) 1... d5 1/2-1/2
+`
+
+func writeTemp(t *testing.T, dir, name, contents string) string {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("Could not write %v: %v", path, err)
+	}
+	return path
+}
+
+func TestIndexedFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pgnindex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := writeTemp(t, dir, "games.pgn", indexGames)
+
+	f, err := OpenIndexed(path, "")
+	if err != nil {
+		t.Fatalf("OpenIndexed() error = %v", err)
+	}
+	defer f.Close()
+
+	if f.Len() != 3 {
+		t.Fatalf("Len() = %v, want 3", f.Len())
+	}
+
+	game, err := f.Game(1)
+	if err != nil {
+		t.Fatalf("Game(1) error = %v", err)
+	}
+	if game.Tags["Event"] != "Second" || len(game.Moves) != 4 || game.Moves[3].Move != "Qh4#" {
+		t.Errorf("Game(1) = %v", game)
+	}
+
+	if _, ok := f.Entry(0).Tags["Opening"]; ok {
+		t.Errorf("Entry(0) stored a tag that is not in IndexTags")
+	}
+	if got := f.Find("Black", "Alice"); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("Find(Black, Alice) = %v, want [1 2]", got)
+	}
+
+	// Games with variations can be indexed even though the parser can't read them yet
+	e := f.Entry(2)
+	if !strings.HasSuffix(indexGames[e.Offset:e.Offset+e.Length], "1... d5 1/2-1/2") {
+		t.Errorf("Entry(2) = %q", indexGames[e.Offset:e.Offset+e.Length])
+	}
+
+	if _, err := f.Game(3); err == nil {
+		t.Errorf("Game(3) expected an out of range error")
+	}
+}
+
+func TestIndexedFileAppend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pgnindex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The second game is still being written and has no result yet
+	path := writeTemp(t, dir, "games.pgn", game1+"\n\n[Event \"Partial\"]\n\n1. e4 e5")
+	indexPath := filepath.Join(dir, "games.index")
+
+	f, err := OpenIndexed(path, indexPath)
+	if err != nil {
+		t.Fatalf("OpenIndexed() error = %v", err)
+	}
+	if f.Len() != 1 {
+		t.Errorf("Len() = %v, want 1", f.Len())
+	}
+	f.Close()
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString(" 2. Nf3 *\n\n" + game1 + "\n")
+	file.Close()
+
+	f, err = OpenIndexed(path, indexPath)
+	if err != nil {
+		t.Fatalf("OpenIndexed() after append error = %v", err)
+	}
+	defer f.Close()
+
+	if f.Len() != 3 {
+		t.Fatalf("Len() after append = %v, want 3", f.Len())
+	}
+	game, err := f.Game(1)
+	if err != nil {
+		t.Fatalf("Game(1) error = %v", err)
+	}
+	if game.Tags["Event"] != "Partial" || len(game.Moves) != 3 {
+		t.Errorf("Game(1) = %v", game)
+	}
+	game, err = f.Game(2)
+	if err != nil || game.Tags["White"] != "BFG9k" {
+		t.Errorf("Game(2) = %v, %v", game, err)
+	}
+
+	if n, err := f.Update(); err != nil || n != 0 {
+		t.Errorf("Update() with nothing appended = %v, %v", n, err)
+	}
+}
+
+func TestIndexedFileTruncated(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pgnindex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := writeTemp(t, dir, "games.pgn", game1+"\n\n"+game1+"\n")
+	f, err := OpenIndexed(path, "")
+	if err != nil {
+		t.Fatalf("OpenIndexed() error = %v", err)
+	}
+	f.Close()
+
+	writeTemp(t, dir, "games.pgn", indexGames)
+	f, err = OpenIndexed(path, "")
+	if err != nil {
+		t.Fatalf("OpenIndexed() after rewrite error = %v", err)
+	}
+	defer f.Close()
+	if f.Len() != 3 {
+		t.Errorf("Len() after the file shrank = %v, want 3", f.Len())
+	}
+}
+
+func TestIndexedFileNoResult(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pgnindex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// The first game ends without a result, the scan picks up again at the next game's tags
+	path := writeTemp(t, dir, "games.pgn", "[Event \"Unfinished\"]\n\n1. e4 e5\n\n"+game1+"\n")
+	f, err := OpenIndexed(path, "")
+	if err != nil {
+		t.Fatalf("OpenIndexed() error = %v", err)
+	}
+	defer f.Close()
+
+	if f.Len() != 1 {
+		t.Fatalf("Len() = %v, want 1", f.Len())
+	}
+	game, err := f.Game(0)
+	if err != nil || game.Tags["White"] != "BFG9k" {
+		t.Errorf("Game(0) = %v, %v", game, err)
+	}
+}
+
+func TestIndexedFileRewritten(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pgnindex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := writeTemp(t, dir, "games.pgn", indexGames)
+	f, err := OpenIndexed(path, "")
+	if err != nil {
+		t.Fatalf("OpenIndexed() error = %v", err)
+	}
+	f.Close()
+
+	// The file is replaced by one at least as long with different games at the indexed offsets
+	writeTemp(t, dir, "games.pgn", game1+"\n\n"+game1+"\n\n"+game1+"\n\n"+game1+"\n")
+	f, err = OpenIndexed(path, "")
+	if err != nil {
+		t.Fatalf("OpenIndexed() after rewrite error = %v", err)
+	}
+	defer f.Close()
+	if f.Len() != 4 {
+		t.Fatalf("Len() after the file was rewritten = %v, want 4", f.Len())
+	}
+	for i := 0; i < f.Len(); i++ {
+		if got := f.Entry(i).Tags["White"]; got != "BFG9k" {
+			t.Errorf("Entry(%v) White = %v, want BFG9k", i, got)
+		}
+	}
+}
@@ -24,7 +24,8 @@ func isIdentChar(ch rune) bool {
 		ch == '=' ||
 		ch == '+' ||
 		ch == '#' ||
-		ch == '-'
+		ch == '-' ||
+		ch == '@'
 }
 
 func (ps *Scanner) scanWhitespace() Token {
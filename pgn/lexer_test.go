@@ -312,4 +312,25 @@ var testers = []scannerTest{
 			},
 		},
 	},
+	scannerTest{
+		name:   `Crazyhouse drops`,
+		phrase: `12. N@f3+ P@e6`,
+		tokens: []Token{
+			Token{
+				Tok:     MoveNumber,
+				Literal: "12",
+			},
+			Token{
+				Tok:     Ident,
+				Literal: `N@f3+`,
+			},
+			Token{
+				Tok:     Ident,
+				Literal: `P@e6`,
+			},
+			Token{
+				Tok: EOF,
+			},
+		},
+	},
 }
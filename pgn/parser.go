@@ -148,6 +148,9 @@ func (p *parser) recover(isStart bool) {
 	// and read all the way up to but not including the brace
 	for {
 		char1 := p.p.s.Next()
+		if char1 == eof {
+			return
+		}
 		if char1 != '\n' {
 			continue
 		}
@@ -287,7 +290,7 @@ Loop:
 
 		// Check for comment
 		if tok.Tok == Comment {
-			move.Annotation = strings.Trim(tok.Literal, " ")
+			move.SetComment(tok.Literal)
 			tok = p.p.Next()
 		}
 
@@ -319,6 +322,19 @@ func (p *parser) parseMoveStr() (string, error) {
 		return tok.Literal, nil
 	}
 
+	// Crazyhouse drops such as N@f3 or P@e6
+	if len(tok.Literal) >= 4 && tok.Literal[1] == '@' {
+		if !strings.ContainsRune("PNBRQ", rune(tok.Literal[0])) {
+			return "", invalidToken("move", tok)
+		}
+		file, rank := tok.Literal[2], tok.Literal[3]
+		if file < 'a' || file > 'h' || rank < '1' || rank > '8' || strings.Trim(tok.Literal[4:], "+#") != "" {
+			return "", invalidToken("move", tok)
+		}
+
+		return tok.Literal, nil
+	}
+
 	firstChar := tok.Literal[0]
 	if firstChar != 'N' && firstChar != 'B' && firstChar != 'R' && firstChar != 'Q' && firstChar != 'K' && (firstChar < 'a' || firstChar > 'h') {
 		return "", invalidToken("move", tok)
@@ -350,8 +366,13 @@ func consolicateErrors(msg string, errorList []error) error {
 		return nil
 	}
 
+	shown := errorList
+	if len(shown) > 10 {
+		shown = shown[0:10]
+	}
+
 	var errs string
-	for i, err := range errorList[0:10] {
+	for i, err := range shown {
 		errs += fmt.Sprintf("\t%v. %v\n\n", i, err)
 	}
 	if len(errorList) > 10 {
@@ -341,6 +341,31 @@ func Test_parser_parseMoves(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name:   "Crazyhouse moves",
+			phrase: `1. e4 d5 2. exd5 Qxd5 3. N@f3 1-0`,
+			want: []Move{
+				Move{
+					Number: 1,
+					Move:   "e4",
+				},
+				Move{
+					Move: "d5",
+				},
+				Move{
+					Number: 2,
+					Move:   "exd5",
+				},
+				Move{
+					Move: "Qxd5",
+				},
+				Move{
+					Number: 3,
+					Move:   "N@f3",
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name:   "Move with nag",
 			phrase: `1. e4 !`,
@@ -490,6 +515,30 @@ func Test_parser_parseMove(t *testing.T) {
 			want:    "Ncxd2",
 			wantErr: false,
 		},
+		{
+			name:    "Crazyhouse drop",
+			phrase:  `N@f3`,
+			want:    "N@f3",
+			wantErr: false,
+		},
+		{
+			name:    "Crazyhouse pawn drop with check",
+			phrase:  `P@e6+`,
+			want:    "P@e6+",
+			wantErr: false,
+		},
+		{
+			name:    "Dropping a king",
+			phrase:  `K@e6`,
+			want:    "",
+			wantErr: true,
+		},
+		{
+			name:    "Drop off the board",
+			phrase:  `Q@e9`,
+			want:    "",
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
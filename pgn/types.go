@@ -1,5 +1,7 @@
 package pgn
 
+import "time"
+
 // Game is a structure representing a complete chess game containing metadata (Tags) and the actual
 // moves that made up the chess game (Moves).
 type Game struct {
@@ -17,6 +19,13 @@ type Move struct {
 	Move string
 	// Annotation is a comment assigned to the move
 	Annotation string
+	// Clock is the time left on the mover's clock from a [%clk] command in the comment
+	Clock *time.Duration
+	// Elapsed is the time spent on the move from an [%emt] command
+	Elapsed *time.Duration
+	// Timestamp is the time spent on the move from a chess.com [%timestamp] command, which is
+	// written in tenths of a second
+	Timestamp *time.Duration
 	// Nag is a Numeric Annotation Glyph (ie. !! or !? or one of those crazy chess characters)
 	Nag string
 	// Alternatices is a list of alternate moves and refutations that could have been played instead of this move
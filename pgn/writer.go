@@ -0,0 +1,165 @@
+package pgn
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// lineLength is the maximum length of a movetext line, as recommended by the pgn export format
+const lineLength = 79
+
+// sevenTagRoster are the tags that are always written first and in this order
+var sevenTagRoster = []string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+// Write writes a list of games to w in pgn format separated by blank lines
+func Write(w io.Writer, games []Game) error {
+	bw := bufio.NewWriter(w)
+	for i, game := range games {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		writeGame(bw, game)
+	}
+	return bw.Flush()
+}
+
+// WriteGame writes a single game to w in pgn format
+func WriteGame(w io.Writer, game Game) error {
+	bw := bufio.NewWriter(w)
+	writeGame(bw, game)
+	return bw.Flush()
+}
+
+// String returns the game in pgn format
+func (g Game) String() string {
+	var sb strings.Builder
+	_ = WriteGame(&sb, g)
+	return sb.String()
+}
+
+func writeGame(w *bufio.Writer, game Game) {
+	for _, key := range tagOrder(game.Tags) {
+		fmt.Fprintf(w, "[%s \"%s\"]\n", key, escapeTag(game.Tags[key]))
+	}
+	w.WriteString("\n")
+
+	number, white := game.StartingMove()
+	tokens := moveTokens(game.Moves, number, white)
+
+	result := game.Tags["Result"]
+	if result == "" {
+		result = "*"
+	}
+	tokens = append(tokens, result)
+
+	writeWrapped(w, tokens)
+}
+
+// tagOrder returns the seven tag roster tags that are present followed by all other tags
+// sorted alphabetically
+func tagOrder(tags map[string]string) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, key := range sevenTagRoster {
+		if _, ok := tags[key]; ok {
+			keys = append(keys, key)
+			seen[key] = true
+		}
+	}
+
+	var rest []string
+	for key := range tags {
+		if !seen[key] {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+
+	return append(keys, rest...)
+}
+
+func escapeTag(value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	return strings.Replace(value, `"`, `\"`, -1)
+}
+
+// StartingMove returns the move number of the first move in the game and whether white plays it.
+// Games with a FEN tag start from the position it describes, all others from move one with white.
+func (g Game) StartingMove() (int32, bool) {
+	fields := strings.Fields(g.Tags["FEN"])
+	if len(fields) < 6 {
+		return 1, true
+	}
+
+	n, err := strconv.Atoi(fields[5])
+	if err != nil || n < 1 {
+		n = 1
+	}
+	return int32(n), fields[1] != "b"
+}
+
+// moveTokens turns a list of moves into movetext tokens. Black moves get a `N...` move number
+// when they start the list or follow a comment or variation.
+func moveTokens(moves []Move, number int32, white bool) []string {
+	var tokens []string
+	needNumber := true
+
+	for _, move := range moves {
+		if white {
+			tokens = append(tokens, fmt.Sprintf("%d.", number))
+		} else if needNumber {
+			tokens = append(tokens, fmt.Sprintf("%d...", number))
+		}
+		needNumber = false
+
+		tokens = append(tokens, move.Move)
+		if move.Nag != "" {
+			tokens = append(tokens, move.Nag)
+		}
+		if comment := commentTokens(move); len(comment) > 0 {
+			// Comments are split into words so long comments can wrap over several lines
+			tokens = append(tokens, "{")
+			for _, word := range comment {
+				tokens = append(tokens, strings.Replace(word, "}", ")", -1))
+			}
+			tokens = append(tokens, "}")
+			needNumber = true
+		}
+		if len(move.Alternatives) > 0 {
+			variation := moveTokens(move.Alternatives, number, white)
+			variation[0] = "(" + variation[0]
+			variation[len(variation)-1] += ")"
+			tokens = append(tokens, variation...)
+			needNumber = true
+		}
+
+		if !white {
+			number++
+		}
+		white = !white
+	}
+
+	return tokens
+}
+
+// writeWrapped writes tokens separated by spaces breaking lines before they grow past lineLength
+func writeWrapped(w *bufio.Writer, tokens []string) {
+	length := 0
+	for _, tok := range tokens {
+		if length > 0 && length+1+len(tok) > lineLength {
+			w.WriteString("\n")
+			length = 0
+		}
+		if length > 0 {
+			w.WriteString(" ")
+			length++
+		}
+		w.WriteString(tok)
+		length += len(tok)
+	}
+	w.WriteString("\n")
+}
@@ -0,0 +1,125 @@
+package pgn
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteGame(t *testing.T) {
+	tests := []struct {
+		name string
+		game Game
+		want string
+	}{
+		{
+			name: "Tags and moves",
+			game: Game{
+				Tags: map[string]string{
+					"Result":   "1-0",
+					"White":    "Fabiano \"Fabi\" Caruana",
+					"Event":    "Rated Classical game",
+					"WhiteElo": "2800",
+					"ECO":      "C00",
+				},
+				Moves: []Move{
+					Move{Number: 1, Move: "e4"},
+					Move{Move: "e6", Nag: "?!"},
+					Move{Number: 2, Move: "d4", Annotation: "The main line"},
+					Move{Move: "d5"},
+				},
+			},
+			want: `[Event "Rated Classical game"]
+[White "Fabiano \"Fabi\" Caruana"]
+[Result "1-0"]
+[ECO "C00"]
+[WhiteElo "2800"]
+
+1. e4 e6 ?! 2. d4 { The main line } 2... d5 1-0
+`,
+		},
+		{
+			name: "Black to move from FEN",
+			game: Game{
+				Tags: map[string]string{
+					"FEN": "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1",
+				},
+				Moves: []Move{
+					Move{Move: "c5"},
+					Move{Move: "Nf3", Alternatives: []Move{Move{Move: "Nc3"}, Move{Move: "Nc6"}}},
+					Move{Move: "d6"},
+				},
+			},
+			want: `[FEN "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1"]
+
+1... c5 2. Nf3 (2. Nc3 Nc6) 2... d6 *
+`,
+		},
+		{
+			name: "Clock commands",
+			game: Game{
+				Moves: []Move{
+					Move{Number: 1, Move: "e4", Clock: duration(3*time.Minute + 12*time.Second), Annotation: "Fast"},
+					Move{Move: "e5", Elapsed: duration(1500 * time.Millisecond), Timestamp: duration(15 * time.Second / 10)},
+				},
+			},
+			want: `
+1. e4 { [%clk 0:03:12] Fast } 1... e5 { [%emt 0:00:01.5] [%timestamp 15] } *
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			if err := WriteGame(&sb, tt.game); err != nil {
+				t.Fatalf("WriteGame() error = %v", err)
+			}
+			if got := sb.String(); got != tt.want {
+				t.Errorf("WriteGame() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	games, err := Parse(strings.NewReader(game1 + "\n\n" + game1))
+	if err != nil {
+		t.Fatalf("Could not parse game: %v", err)
+	}
+	games[1].Moves[3].Annotation = "A long comment that should wrap onto the next line when the movetext is written out"
+	games[1].Moves[4].Nag = "!!"
+
+	var sb strings.Builder
+	if err := Write(&sb, games); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	for _, line := range strings.Split(sb.String(), "\n") {
+		if len(line) > lineLength {
+			t.Errorf("Write() produced a line longer than %v characters: %q", lineLength, line)
+		}
+	}
+
+	got, err := Parse(strings.NewReader(sb.String()))
+	if err != nil {
+		t.Fatalf("Could not parse written games: %v\n%v", err, sb.String())
+	}
+	if len(got) != len(games) {
+		t.Fatalf("Parsed %v written games, want %v", len(got), len(games))
+	}
+	for i := range games {
+		if len(got[i].Moves) != len(games[i].Moves) {
+			t.Fatalf("Game %v has %v moves after writing, want %v", i, len(got[i].Moves), len(games[i].Moves))
+		}
+		for j, m := range games[i].Moves {
+			g := got[i].Moves[j]
+			// Wrapping turns spaces in long comments into new lines
+			annotation := strings.Join(strings.Fields(g.Annotation), " ")
+			if g.Move != m.Move || g.Nag != m.Nag || annotation != m.Annotation {
+				t.Errorf("Game %v move %v = %+v after writing, want %+v", i, j, g, m)
+			}
+		}
+		if len(got[i].Tags) != len(games[i].Tags) {
+			t.Errorf("Game %v has %v tags after writing, want %v", i, len(got[i].Tags), len(games[i].Tags))
+		}
+	}
+}
@@ -0,0 +1,497 @@
+// Package pgnbin is a compact binary storage format for chess games.
+//
+// A file is a header followed by blocks of games. Each block is compressed on its own with
+// DEFLATE and holds a table of the distinct moves in the block, a table of the other strings
+// (tag names, tag values, NAGs and comments) and then the games, which refer to both by index.
+// The move table is sorted by how often each move is played, so before compression the 64 most
+// common moves of a block take one byte each and the rest two or three. Move numbers are worked
+// out from the position of a move in the game and only stored when they differ from that.
+//
+// Moves are stored as SAN strings, so games are read back without replaying them.
+package pgnbin
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+
+	"github.com/schafer14/go-chess/pgn"
+)
+
+// magic starts every file and identifies the format version
+const magic = "PGNBIN2\n"
+
+// DefaultBlockSize is the number of games stored in each compressed block
+const DefaultBlockSize = 1000
+
+// maxBlockLen guards against allocating huge buffers when reading a corrupt file
+const maxBlockLen = 1 << 30
+
+// Each move starts with a uvarint holding its index in the move table shifted left by one. The
+// low bit is set when the move has a NAG, comment, variations or an unexpected move number, and
+// a uvarint of flags saying which follows.
+const (
+	flagNumber = 1 << iota
+	flagNag
+	flagAnnotation
+	flagAlternatives
+)
+
+// ErrFormat is returned when reading data that is not in the pgnbin format
+var ErrFormat = errors.New("pgnbin: invalid format")
+
+// Writer writes games in the binary format. Games are buffered until a block is full, so Close
+// must be called to write the last block.
+type Writer struct {
+	// BlockSize is the number of games in each block. It may be changed before the first call
+	// to Write.
+	BlockSize int
+	// Level is the flate compression level
+	Level int
+
+	w       *bufio.Writer
+	pending []pgn.Game
+}
+
+// NewWriter writes the file header to w and returns a Writer
+func NewWriter(w io.Writer) (*Writer, error) {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.WriteString(magic); err != nil {
+		return nil, err
+	}
+	return &Writer{BlockSize: DefaultBlockSize, Level: flate.DefaultCompression, w: bw}, nil
+}
+
+// Write adds a game to the file
+func (w *Writer) Write(game pgn.Game) error {
+	w.pending = append(w.pending, game)
+	if len(w.pending) >= w.BlockSize {
+		return w.flushBlock()
+	}
+	return nil
+}
+
+// Close writes any buffered games. It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if len(w.pending) > 0 {
+		if err := w.flushBlock(); err != nil {
+			return err
+		}
+	}
+	return w.w.Flush()
+}
+
+func (w *Writer) flushBlock() error {
+	e := newBlockEncoder(w.pending)
+	for _, g := range w.pending {
+		e.game(g)
+	}
+
+	var raw bytes.Buffer
+	putStrings(&raw, e.moveTable)
+	putStrings(&raw, e.strings)
+	putUvarint(&raw, uint64(len(w.pending)))
+	raw.Write(e.games.Bytes())
+
+	var compressed bytes.Buffer
+	fw, err := flate.NewWriter(&compressed, w.Level)
+	if err != nil {
+		return err
+	}
+	if _, err := fw.Write(raw.Bytes()); err != nil {
+		return err
+	}
+	if err := fw.Close(); err != nil {
+		return err
+	}
+
+	var lenBuf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lenBuf[:], uint64(compressed.Len()))
+	if _, err := w.w.Write(lenBuf[:n]); err != nil {
+		return err
+	}
+	if _, err := w.w.Write(compressed.Bytes()); err != nil {
+		return err
+	}
+
+	w.pending = w.pending[:0]
+	return nil
+}
+
+// blockEncoder encodes the games of one block, interning strings as it goes
+type blockEncoder struct {
+	moveTable []string
+	moveIndex map[string]uint64
+	strings   []string
+	index     map[string]uint64
+	games     bytes.Buffer
+}
+
+// newBlockEncoder returns an encoder whose move table holds the moves of games, most played first
+func newBlockEncoder(games []pgn.Game) *blockEncoder {
+	e := &blockEncoder{moveIndex: make(map[string]uint64), index: make(map[string]uint64)}
+
+	counts := make(map[string]int)
+	var count func(moves []pgn.Move)
+	count = func(moves []pgn.Move) {
+		for _, m := range moves {
+			counts[m.Move]++
+			count(m.Alternatives)
+		}
+	}
+	for _, g := range games {
+		count(g.Moves)
+	}
+
+	for move := range counts {
+		e.moveTable = append(e.moveTable, move)
+	}
+	sort.Slice(e.moveTable, func(i, j int) bool {
+		a, b := e.moveTable[i], e.moveTable[j]
+		if counts[a] != counts[b] {
+			return counts[a] > counts[b]
+		}
+		return a < b
+	})
+	for i, move := range e.moveTable {
+		e.moveIndex[move] = uint64(i)
+	}
+	return e
+}
+
+func (e *blockEncoder) intern(s string) uint64 {
+	i, ok := e.index[s]
+	if !ok {
+		i = uint64(len(e.strings))
+		e.strings = append(e.strings, s)
+		e.index[s] = i
+	}
+	return i
+}
+
+func (e *blockEncoder) game(g pgn.Game) {
+	putUvarint(&e.games, uint64(len(g.Tags)))
+	for _, key := range sortedKeys(g.Tags) {
+		putUvarint(&e.games, e.intern(key))
+		putUvarint(&e.games, e.intern(g.Tags[key]))
+	}
+	e.moves(g.Moves, newNumbering(g.StartingMove()))
+}
+
+func (e *blockEncoder) moves(moves []pgn.Move, n numbering) {
+	putUvarint(&e.games, uint64(len(moves)))
+	for _, m := range moves {
+		var flags uint64
+		if m.Number != n.number() {
+			flags |= flagNumber
+		}
+		if m.Nag != "" {
+			flags |= flagNag
+		}
+		comment := m.Comment()
+		if comment != "" {
+			flags |= flagAnnotation
+		}
+		if len(m.Alternatives) > 0 {
+			flags |= flagAlternatives
+		}
+
+		if flags == 0 {
+			putUvarint(&e.games, e.moveIndex[m.Move]<<1)
+		} else {
+			putUvarint(&e.games, e.moveIndex[m.Move]<<1|1)
+			putUvarint(&e.games, flags)
+		}
+		if flags&flagNumber != 0 {
+			putUvarint(&e.games, uint64(m.Number))
+		}
+		if m.Nag != "" {
+			putUvarint(&e.games, e.intern(m.Nag))
+		}
+		if comment != "" {
+			putUvarint(&e.games, e.intern(comment))
+		}
+		if len(m.Alternatives) > 0 {
+			e.moves(m.Alternatives, n.variation())
+		}
+		n.next(flags&(flagAnnotation|flagAlternatives) != 0)
+	}
+}
+
+// numbering follows the move numbers of a list of moves the way they are written in pgn: white
+// moves are numbered, and so are black moves that start the list or come after a comment or a
+// variation.
+type numbering struct {
+	move       int32
+	white      bool
+	needNumber bool
+}
+
+func newNumbering(move int32, white bool) numbering {
+	return numbering{move: move, white: white, needNumber: true}
+}
+
+// number returns the expected Number of the next move
+func (n numbering) number() int32 {
+	if n.white || n.needNumber {
+		return n.move
+	}
+	return 0
+}
+
+// variation returns the numbering of a variation replacing the next move
+func (n numbering) variation() numbering {
+	return newNumbering(n.move, n.white)
+}
+
+// next moves the numbering past a move. Interrupted is set when the move has a comment or
+// variations, so the move after it is numbered.
+func (n *numbering) next(interrupted bool) {
+	n.needNumber = interrupted
+	if !n.white {
+		n.move++
+	}
+	n.white = !n.white
+}
+
+// Reader reads games written by a Writer
+type Reader struct {
+	r     *bufio.Reader
+	block []pgn.Game
+	pos   int
+}
+
+// NewReader checks the file header and returns a Reader
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(magic))
+	if _, err := io.ReadFull(br, header); err != nil || string(header) != magic {
+		return nil, ErrFormat
+	}
+	return &Reader{r: br}, nil
+}
+
+// Next returns the next game in the file or io.EOF when there are no more games
+func (r *Reader) Next() (pgn.Game, error) {
+	for r.pos >= len(r.block) {
+		if err := r.readBlock(); err != nil {
+			return pgn.Game{}, err
+		}
+	}
+
+	g := r.block[r.pos]
+	r.pos++
+	return g, nil
+}
+
+func (r *Reader) readBlock() error {
+	n, err := binary.ReadUvarint(r.r)
+	if err == io.EOF {
+		return io.EOF
+	}
+	if err != nil || n > maxBlockLen {
+		return ErrFormat
+	}
+
+	compressed := make([]byte, n)
+	if _, err := io.ReadFull(r.r, compressed); err != nil {
+		return ErrFormat
+	}
+	raw, err := ioutil.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	if err != nil {
+		return fmt.Errorf("pgnbin: reading block: %v", err)
+	}
+
+	d := blockDecoder{buf: raw}
+	d.moveTable = d.table()
+	d.strings = d.table()
+
+	games := d.uvarint()
+	r.block = r.block[:0]
+	r.pos = 0
+	for i := uint64(0); i < games && d.err == nil; i++ {
+		r.block = append(r.block, d.game())
+	}
+
+	return d.err
+}
+
+// blockDecoder reads values from a decompressed block. The first error is kept and all reads
+// after it return zero values.
+type blockDecoder struct {
+	buf       []byte
+	moveTable []string
+	strings   []string
+	err       error
+}
+
+func (d *blockDecoder) table() []string {
+	count := d.uvarint()
+	// Every string takes at least one byte for its length
+	if count > uint64(len(d.buf)) {
+		d.err = ErrFormat
+		return nil
+	}
+	table := make([]string, 0, count)
+	for i := uint64(0); i < count && d.err == nil; i++ {
+		table = append(table, string(d.bytes(d.uvarint())))
+	}
+	return table
+}
+
+func (d *blockDecoder) uvarint() uint64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(d.buf)
+	if n <= 0 {
+		d.err = ErrFormat
+		return 0
+	}
+	d.buf = d.buf[n:]
+	return v
+}
+
+func (d *blockDecoder) bytes(n uint64) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n > uint64(len(d.buf)) {
+		d.err = ErrFormat
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *blockDecoder) str(i uint64) string {
+	return d.lookup(d.strings, i)
+}
+
+func (d *blockDecoder) lookup(table []string, i uint64) string {
+	if d.err != nil {
+		return ""
+	}
+	if i >= uint64(len(table)) {
+		d.err = ErrFormat
+		return ""
+	}
+	return table[i]
+}
+
+func (d *blockDecoder) game() pgn.Game {
+	var g pgn.Game
+	n := d.uvarint()
+	if n > uint64(len(d.buf)) {
+		d.err = ErrFormat
+		return g
+	}
+	g.Tags = make(map[string]string, n)
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		key := d.str(d.uvarint())
+		g.Tags[key] = d.str(d.uvarint())
+	}
+	g.Moves = d.moves(newNumbering(g.StartingMove()))
+	return g
+}
+
+func (d *blockDecoder) moves(num numbering) []pgn.Move {
+	n := d.uvarint()
+	if n == 0 || n > uint64(len(d.buf)) {
+		// Every move takes at least one byte so longer lists are corrupt
+		if n > 0 {
+			d.err = ErrFormat
+		}
+		return nil
+	}
+
+	moves := make([]pgn.Move, 0, n)
+	for i := uint64(0); i < n && d.err == nil; i++ {
+		v := d.uvarint()
+		m := pgn.Move{Move: d.lookup(d.moveTable, v>>1), Number: num.number()}
+		var flags uint64
+		if v&1 != 0 {
+			flags = d.uvarint()
+		}
+		if flags&flagNumber != 0 {
+			m.Number = int32(d.uvarint())
+		}
+		if flags&flagNag != 0 {
+			m.Nag = d.str(d.uvarint())
+		}
+		if flags&flagAnnotation != 0 {
+			m.SetComment(d.str(d.uvarint()))
+		}
+		if flags&flagAlternatives != 0 {
+			m.Alternatives = d.moves(num.variation())
+		}
+		num.next(flags&(flagAnnotation|flagAlternatives) != 0)
+		moves = append(moves, m)
+	}
+	return moves
+}
+
+// Encode writes a list of games to w in the binary format
+func Encode(w io.Writer, games []pgn.Game) error {
+	bw, err := NewWriter(w)
+	if err != nil {
+		return err
+	}
+	for _, g := range games {
+		if err := bw.Write(g); err != nil {
+			return err
+		}
+	}
+	return bw.Close()
+}
+
+// Decode reads all games from r
+func Decode(r io.Reader) ([]pgn.Game, error) {
+	br, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var games []pgn.Game
+	for {
+		g, err := br.Next()
+		if err == io.EOF {
+			return games, nil
+		}
+		if err != nil {
+			return games, err
+		}
+		games = append(games, g)
+	}
+}
+
+// putStrings writes the number of strings followed by each string as a length and its bytes
+func putStrings(buf *bytes.Buffer, strings []string) {
+	putUvarint(buf, uint64(len(strings)))
+	for _, s := range strings {
+		putUvarint(buf, uint64(len(s)))
+		buf.WriteString(s)
+	}
+}
+
+func putUvarint(buf *bytes.Buffer, v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	buf.Write(b[:n])
+}
+
+func sortedKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
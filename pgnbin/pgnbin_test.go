@@ -0,0 +1,214 @@
+package pgnbin
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/schafer14/go-chess/pgn"
+)
+
+const game = `[Event "Rated Classical game"]
+[Site "https://lichess.org/j1dkb5dw"]
+[White "BFG9k"]
+[Black "mamalak"]
+[Result "1-0"]
+[WhiteElo "1639"]
+[BlackElo "1403"]
+[ECO "C00"]
+[TimeControl "600+8"]
+
+1. e4 e6 2. d4 b6 3. a3 Bb7 4. Nc3 Nh6 5. Bxh6 gxh6 6. Be2 Qg5 7. Bg4 h5 8. Nf3 Qg6 9. Nh4 Qg5 10. Bxh5 Qxh4 11. Qf3 Kd8 12. Qxf7 Nc6 13. Qe8# 1-0
+`
+
+// testGames returns n copies of the test game with a few differences between them
+func testGames(t testing.TB, n int) []pgn.Game {
+	var games []pgn.Game
+	for i := 0; i < n; i++ {
+		parsed, err := pgn.Parse(strings.NewReader(game))
+		if err != nil {
+			t.Fatalf("Could not parse test game: %v", err)
+		}
+		g := parsed[0]
+		g.Tags["Round"] = fmt.Sprint(i + 1)
+		games = append(games, g)
+	}
+
+	games[0].Moves[1].Nag = "?!"
+	games[0].Moves[2].Annotation = "[%eval 0.3] The main line\n\nSee   below"
+	clock := 9*time.Minute + 58*time.Second
+	games[0].Moves[2].Clock = &clock
+	games[0].Moves[3].Alternatives = []pgn.Move{
+		{Move: "d5", Annotation: "More common"},
+		{Number: 3, Move: "e5", Alternatives: []pgn.Move{{Move: "Nc3"}}},
+	}
+	return games
+}
+
+// variedGames returns n games between different players with moves drawn at random, common
+// moves far more often than rare ones
+func variedGames(n int) []pgn.Game {
+	var vocabulary []string
+	for _, piece := range []string{"", "N", "B", "R", "Q", "K"} {
+		for file := 'a'; file <= 'h'; file++ {
+			for rank := '1'; rank <= '8'; rank++ {
+				square := string(file) + string(rank)
+				vocabulary = append(vocabulary, piece+square, piece+"x"+square)
+			}
+		}
+	}
+	r := rand.New(rand.NewSource(1))
+	zipf := rand.NewZipf(r, 1.1, 8, uint64(len(vocabulary)-1))
+	results := []string{"1-0", "0-1", "1/2-1/2"}
+
+	var games []pgn.Game
+	for i := 0; i < n; i++ {
+		g := pgn.Game{Tags: map[string]string{
+			"Event":  "Varied",
+			"Site":   "?",
+			"Date":   fmt.Sprintf("2019.%02d.%02d", r.Intn(12)+1, r.Intn(28)+1),
+			"Round":  fmt.Sprint(i + 1),
+			"White":  fmt.Sprintf("Player %d", r.Intn(200)),
+			"Black":  fmt.Sprintf("Player %d", r.Intn(200)),
+			"Result": results[r.Intn(len(results))],
+		}}
+		plies := 40 + r.Intn(80)
+		for ply := 0; ply < plies; ply++ {
+			m := pgn.Move{Move: vocabulary[zipf.Uint64()]}
+			if ply%2 == 0 {
+				m.Number = int32(ply/2 + 1)
+			}
+			g.Moves = append(g.Moves, m)
+		}
+		games = append(games, g)
+	}
+	return games
+}
+
+func TestRoundTrip(t *testing.T) {
+	games := testGames(t, 25)
+	games = append(games, pgn.Game{Tags: map[string]string{"Event": "No moves"}})
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+	w.BlockSize = 10
+	for _, g := range games {
+		if err := w.Write(g); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	got, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, games) {
+		t.Errorf("Decode() did not return the encoded games\ngot  %v\nwant %v", got, games)
+	}
+}
+
+func TestSize(t *testing.T) {
+	games := testGames(t, 1000)
+
+	var text bytes.Buffer
+	if err := pgn.Write(&text, games); err != nil {
+		t.Fatal(err)
+	}
+	var bin bytes.Buffer
+	if err := Encode(&bin, games); err != nil {
+		t.Fatal(err)
+	}
+
+	if bin.Len()*4 > text.Len() {
+		t.Errorf("Encoded %v games in %v bytes, expected well under a quarter of the %v bytes of pgn", len(games), bin.Len(), text.Len())
+	}
+}
+
+func TestSize_varied(t *testing.T) {
+	games := variedGames(1000)
+
+	var text bytes.Buffer
+	if err := pgn.Write(&text, games); err != nil {
+		t.Fatal(err)
+	}
+	var compressed bytes.Buffer
+	fw, err := flate.NewWriter(&compressed, flate.DefaultCompression)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fw.Write(text.Bytes())
+	fw.Close()
+
+	var bin bytes.Buffer
+	if err := Encode(&bin, games); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Decode(bytes.NewReader(bin.Bytes()))
+	if err != nil || !reflect.DeepEqual(got, games) {
+		t.Fatalf("Decode() did not return the encoded games, error = %v", err)
+	}
+
+	if bin.Len()*3 > compressed.Len()*2 {
+		t.Errorf("Encoded %v games in %v bytes, expected under two thirds of the %v bytes of compressed pgn", len(games), bin.Len(), compressed.Len())
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	if _, err := NewReader(strings.NewReader("[Event \"Not binary\"]")); err != ErrFormat {
+		t.Errorf("NewReader() error = %v, want ErrFormat", err)
+	}
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, testGames(t, 3)); err != nil {
+		t.Fatal(err)
+	}
+	truncated := buf.Bytes()[:buf.Len()-5]
+	r, err := NewReader(bytes.NewReader(truncated))
+	if err != nil {
+		t.Fatalf("NewReader() error = %v", err)
+	}
+	if _, err := r.Next(); err == nil || err == io.EOF {
+		t.Errorf("Next() error = %v, want a format error", err)
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	var buf bytes.Buffer
+	if err := Encode(&buf, testGames(b, 1000)); err != nil {
+		b.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Decode(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParsePgn(b *testing.B) {
+	var buf bytes.Buffer
+	if err := pgn.Write(&buf, testGames(b, 1000)); err != nil {
+		b.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Games with variations can't be parsed yet, the rest still give a fair comparison
+		pgn.Parse(bytes.NewReader(data))
+	}
+}
@@ -0,0 +1,213 @@
+// Package polyglot reads and writes Polyglot opening books (.bin files).
+//
+// A book is a list of 16 byte big endian entries sorted by position key. Each entry holds a
+// move that was played from the position along with a weight and learn data. Key hashes a
+// position given as a FEN into its key.
+package polyglot
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+)
+
+// entrySize is the size in bytes of a single book entry
+const entrySize = 16
+
+// Entry is a single book entry
+type Entry struct {
+	// Key is the Polyglot hash of the position the move is played from
+	Key uint64
+	// Move is the raw Polyglot move encoding. Use UCI to get it in coordinate notation.
+	Move   uint16
+	Weight uint16
+	Learn  uint32
+}
+
+// Book is an opening book held in memory
+type Book struct {
+	entries []Entry
+}
+
+// Open reads a book from a file
+func Open(filePath string) (*Book, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Read(file)
+}
+
+// Read reads a book from r. Entries that are out of key order are sorted so lookups keep
+// working on books written by non conforming tools.
+func Read(r io.Reader) (*Book, error) {
+	var book Book
+	var buf [entrySize]byte
+	br := bufio.NewReader(r)
+
+	for {
+		_, err := io.ReadFull(br, buf[:])
+		if err == io.EOF {
+			break
+		}
+		if err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("polyglot: truncated entry after %v entries", len(book.entries))
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		book.entries = append(book.entries, Entry{
+			Key:    binary.BigEndian.Uint64(buf[0:8]),
+			Move:   binary.BigEndian.Uint16(buf[8:10]),
+			Weight: binary.BigEndian.Uint16(buf[10:12]),
+			Learn:  binary.BigEndian.Uint32(buf[12:16]),
+		})
+	}
+
+	if !sort.SliceIsSorted(book.entries, book.less) {
+		sort.SliceStable(book.entries, book.less)
+	}
+
+	return &book, nil
+}
+
+func (b *Book) less(i, j int) bool {
+	return b.entries[i].Key < b.entries[j].Key
+}
+
+// Len returns the number of entries in the book
+func (b *Book) Len() int {
+	return len(b.entries)
+}
+
+// Entries returns all entries for a position key in the order they appear in the book
+func (b *Book) Entries(key uint64) []Entry {
+	start := sort.Search(len(b.entries), func(i int) bool {
+		return b.entries[i].Key >= key
+	})
+
+	end := start
+	for end < len(b.entries) && b.entries[end].Key == key {
+		end++
+	}
+
+	return b.entries[start:end]
+}
+
+// Best returns the entry with the highest weight for a position key
+func (b *Book) Best(key uint64) (Entry, bool) {
+	var best Entry
+	entries := b.Entries(key)
+	if len(entries) == 0 {
+		return best, false
+	}
+
+	best = entries[0]
+	for _, e := range entries[1:] {
+		if e.Weight > best.Weight {
+			best = e
+		}
+	}
+
+	return best, true
+}
+
+// Pick chooses an entry for a position key at random with probability proportional to its
+// weight. If every entry has weight zero they are chosen uniformly.
+func (b *Book) Pick(key uint64, rnd *rand.Rand) (Entry, bool) {
+	entries := b.Entries(key)
+	if len(entries) == 0 {
+		return Entry{}, false
+	}
+
+	var total int
+	for _, e := range entries {
+		total += int(e.Weight)
+	}
+	if total == 0 {
+		return entries[rnd.Intn(len(entries))], true
+	}
+
+	n := rnd.Intn(total)
+	for _, e := range entries {
+		n -= int(e.Weight)
+		if n < 0 {
+			return e, true
+		}
+	}
+
+	return entries[len(entries)-1], true
+}
+
+// UCI returns the move in coordinate notation, ie. e2e4 or a7a8q.
+//
+// Polyglot encodes castling as the king capturing its own rook (e1h1). These moves are converted
+// to the usual king moves (e1g1). As the book does not store which piece moves, a rook moving
+// from e1 to h1 is indistinguishable and is converted too; callers that have the position can
+// check the piece on the from square with From and To instead.
+func (e Entry) UCI() string {
+	from, to := e.From(), e.To()
+
+	switch {
+	case from == "e1" && to == "h1":
+		to = "g1"
+	case from == "e1" && to == "a1":
+		to = "c1"
+	case from == "e8" && to == "h8":
+		to = "g8"
+	case from == "e8" && to == "a8":
+		to = "c8"
+	}
+
+	return from + to + e.Promotion()
+}
+
+// From returns the square the move starts on
+func (e Entry) From() string {
+	return square(int(e.Move>>6) & 0x3f)
+}
+
+// To returns the square the move ends on exactly as stored in the book
+func (e Entry) To() string {
+	return square(int(e.Move) & 0x3f)
+}
+
+// Promotion returns the lower case piece letter a pawn promotes to or an empty string
+func (e Entry) Promotion() string {
+	return [8]string{"", "n", "b", "r", "q", "", "", ""}[(e.Move>>12)&0x7]
+}
+
+// square converts a Polyglot square index (a1 = 0, h8 = 63) to its name
+func square(sq int) string {
+	return string([]byte{byte('a' + sq%8), byte('1' + sq/8)})
+}
+
+// Write writes entries to w in the Polyglot format. Entries are sorted by key first, as the
+// format requires, keeping the given order of entries that share a key.
+func Write(w io.Writer, entries []Entry) error {
+	sorted := append([]Entry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Key < sorted[j].Key
+	})
+
+	bw := bufio.NewWriter(w)
+	var buf [entrySize]byte
+	for _, e := range sorted {
+		binary.BigEndian.PutUint64(buf[0:8], e.Key)
+		binary.BigEndian.PutUint16(buf[8:10], e.Move)
+		binary.BigEndian.PutUint16(buf[10:12], e.Weight)
+		binary.BigEndian.PutUint32(buf[12:16], e.Learn)
+		if _, err := bw.Write(buf[:]); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
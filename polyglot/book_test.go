@@ -0,0 +1,137 @@
+package polyglot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// startKey is the Polyglot key of the standard start position
+const startKey = 0x463b96181691fc9c
+
+// move encodes a move the way Polyglot does from square indexes and a promotion piece
+func move(from, to, promotion int) uint16 {
+	return uint16(promotion<<12 | from<<6 | to)
+}
+
+func encode(entries ...Entry) []byte {
+	var buf bytes.Buffer
+	for _, e := range entries {
+		_ = binary.Write(&buf, binary.BigEndian, e)
+	}
+	return buf.Bytes()
+}
+
+var testEntries = []Entry{
+	{Key: 1, Move: move(12, 28, 0), Weight: 1},
+	{Key: startKey, Move: move(12, 28, 0), Weight: 30, Learn: 7},
+	{Key: startKey, Move: move(11, 27, 0), Weight: 20},
+	{Key: startKey, Move: move(6, 21, 0), Weight: 0},
+	{Key: 0xffffffffffffffff, Move: move(4, 7, 0), Weight: 5},
+}
+
+func TestRead(t *testing.T) {
+	book, err := Read(bytes.NewReader(encode(testEntries...)))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if book.Len() != len(testEntries) {
+		t.Errorf("Len() = %v, want %v", book.Len(), len(testEntries))
+	}
+
+	got := book.Entries(startKey)
+	if !reflect.DeepEqual(got, testEntries[1:4]) {
+		t.Errorf("Entries() = %v, want %v", got, testEntries[1:4])
+	}
+	if len(book.Entries(2)) != 0 {
+		t.Errorf("Entries() returned entries for a missing key")
+	}
+}
+
+func TestReadUnsorted(t *testing.T) {
+	book, err := Read(bytes.NewReader(encode(testEntries[4], testEntries[1], testEntries[0])))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(book.Entries(startKey)) != 1 || len(book.Entries(1)) != 1 {
+		t.Errorf("Entries() could not find entries in an unsorted book")
+	}
+}
+
+func TestReadTruncated(t *testing.T) {
+	data := encode(testEntries...)
+	if _, err := Read(bytes.NewReader(data[:len(data)-3])); err == nil {
+		t.Errorf("Read() expected an error for a truncated book")
+	}
+}
+
+func TestBest(t *testing.T) {
+	book, _ := Read(bytes.NewReader(encode(testEntries...)))
+
+	best, ok := book.Best(startKey)
+	if !ok || best.UCI() != "e2e4" {
+		t.Errorf("Best() = %v %v, want e2e4", best.UCI(), ok)
+	}
+	if _, ok := book.Best(2); ok {
+		t.Errorf("Best() found a move for a missing key")
+	}
+}
+
+func TestPick(t *testing.T) {
+	book, _ := Read(bytes.NewReader(encode(testEntries...)))
+	rnd := rand.New(rand.NewSource(1))
+
+	counts := make(map[string]int)
+	for i := 0; i < 5000; i++ {
+		e, ok := book.Pick(startKey, rnd)
+		if !ok {
+			t.Fatalf("Pick() found no move")
+		}
+		counts[e.UCI()]++
+	}
+
+	if counts["g1f3"] != 0 {
+		t.Errorf("Pick() chose a zero weight move %v times", counts["g1f3"])
+	}
+	if counts["e2e4"] < 2700 || counts["e2e4"] > 3300 {
+		t.Errorf("Pick() chose e2e4 %v times out of 5000, expected about 3000", counts["e2e4"])
+	}
+}
+
+func TestEntryUCI(t *testing.T) {
+	tests := []struct {
+		name string
+		move uint16
+		want string
+	}{
+		{name: "Pawn push", move: move(12, 28, 0), want: "e2e4"},
+		{name: "Knight move", move: move(62, 45, 0), want: "g8f6"},
+		{name: "White castles short", move: move(4, 7, 0), want: "e1g1"},
+		{name: "White castles long", move: move(4, 0, 0), want: "e1c1"},
+		{name: "Black castles short", move: move(60, 63, 0), want: "e8g8"},
+		{name: "Black castles long", move: move(60, 56, 0), want: "e8c8"},
+		{name: "Promotion", move: move(48, 56, 4), want: "a7a8q"},
+		{name: "Under promotion", move: move(9, 0, 1), want: "b2a1n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Entry{Move: tt.move}).UCI(); got != tt.want {
+				t.Errorf("Entry.UCI() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	unsorted := []Entry{testEntries[4], testEntries[1], testEntries[0], testEntries[2], testEntries[3]}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, unsorted); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), encode(testEntries...)) {
+		t.Errorf("Write() did not produce entries sorted by key")
+	}
+}
@@ -0,0 +1,99 @@
+package polyglot
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Offsets into random64 of the numbers that are not for pieces
+const (
+	castleOffset    = 768
+	enPassantOffset = 772
+	turnOffset      = 780
+)
+
+// pieceKinds maps FEN piece letters to their Polyglot kind of piece
+var pieceKinds = map[rune]int{
+	'p': 0, 'P': 1, 'n': 2, 'N': 3, 'b': 4, 'B': 5,
+	'r': 6, 'R': 7, 'q': 8, 'Q': 9, 'k': 10, 'K': 11,
+}
+
+// Key returns the Polyglot key of the position described by a FEN. Only the piece placement,
+// side to move, castling and en passant fields are used. As Polyglot requires, the en passant
+// square only counts when a pawn of the side to move stands next to the pawn that can be taken.
+func Key(fen string) (uint64, error) {
+	invalid := func(reason string) (uint64, error) {
+		return 0, fmt.Errorf("polyglot: invalid FEN %q: %v", fen, reason)
+	}
+
+	fields := strings.Fields(fen)
+	if len(fields) < 4 {
+		return invalid("expected at least four fields")
+	}
+
+	// board holds the piece letters by square index, a1 = 0 and h8 = 63
+	var board [64]rune
+	var key uint64
+
+	ranks := strings.Split(fields[0], "/")
+	if len(ranks) != 8 {
+		return invalid("expected eight ranks")
+	}
+	for i, rank := range ranks {
+		row := 7 - i
+		file := 0
+		for _, c := range rank {
+			if c >= '1' && c <= '8' {
+				file += int(c - '0')
+				continue
+			}
+			kind, ok := pieceKinds[c]
+			if !ok || file > 7 {
+				return invalid(fmt.Sprintf("bad rank %q", rank))
+			}
+			board[row*8+file] = c
+			key ^= random64[64*kind+8*row+file]
+			file++
+		}
+		if file != 8 {
+			return invalid(fmt.Sprintf("rank %q does not have eight squares", rank))
+		}
+	}
+
+	switch fields[1] {
+	case "w":
+		key ^= random64[turnOffset]
+	case "b":
+	default:
+		return invalid("side to move must be w or b")
+	}
+
+	if fields[2] != "-" {
+		seen := make(map[rune]bool)
+		for _, c := range fields[2] {
+			i := strings.IndexRune("KQkq", c)
+			if i < 0 || seen[c] {
+				return invalid(fmt.Sprintf("bad castling rights %q", fields[2]))
+			}
+			seen[c] = true
+			key ^= random64[castleOffset+i]
+		}
+	}
+
+	if ep := fields[3]; ep != "-" {
+		if len(ep) != 2 || ep[0] < 'a' || ep[0] > 'h' || (ep[1] != '3' && ep[1] != '6') {
+			return invalid(fmt.Sprintf("bad en passant square %q", ep))
+		}
+		file := int(ep[0] - 'a')
+		// Pawns that can take en passant stand on the fifth rank from their side
+		row, pawn := 4, 'P'
+		if fields[1] == "b" {
+			row, pawn = 3, 'p'
+		}
+		if (file > 0 && board[row*8+file-1] == pawn) || (file < 7 && board[row*8+file+1] == pawn) {
+			key ^= random64[enPassantOffset+file]
+		}
+	}
+
+	return key, nil
+}
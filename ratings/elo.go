@@ -0,0 +1,111 @@
+package ratings
+
+import (
+	"math"
+	"time"
+
+	"github.com/schafer14/go-chess/pgn"
+)
+
+// EloOptions configure an Elo rating run
+type EloOptions struct {
+	// InitialRating is given to players without an imported rating
+	InitialRating float64
+	// K returns the K-factor of a player at the start of a rating period
+	K func(p *Player) float64
+	// MaxDifference caps the rating difference used for expected scores. FIDE uses 400. Zero
+	// means no cap.
+	MaxDifference float64
+	Period        Period
+}
+
+// FIDE are the Elo options following the FIDE rating regulations: monthly rating periods, the
+// 400 point rule and FIDEK factors.
+var FIDE = EloOptions{
+	InitialRating: 1500,
+	K:             FIDEK,
+	MaxDifference: 400,
+	Period:        Monthly,
+}
+
+// FIDEK returns the FIDE K-factor: 40 for a player's first 30 games, 10 once they have reached
+// 2400 and 20 otherwise. The K of 40 for juniors below 2300 is not applied as ages are not
+// known from pgn tags.
+func FIDEK(p *Player) float64 {
+	switch {
+	case p.Games < 30:
+		return 40
+	case p.Peak >= 2400:
+		return 10
+	default:
+		return 20
+	}
+}
+
+// ExpectedScore returns the expected score of a player rated r against an opponent rated
+// opponent. The difference is capped at maxDifference unless it is zero.
+func ExpectedScore(r, opponent, maxDifference float64) float64 {
+	d := opponent - r
+	if maxDifference > 0 {
+		d = math.Max(-maxDifference, math.Min(maxDifference, d))
+	}
+	return 1 / (1 + math.Pow(10, d/400))
+}
+
+// Elo computes Elo ratings. initial may be nil.
+func Elo(games []pgn.Game, opts EloOptions, initial map[string]Initial) Result {
+	if opts.K == nil {
+		opts.K = FIDEK
+	}
+
+	rated, skipped := chronological(games)
+	players := make(map[string]*Player)
+	player := func(name string) *Player {
+		p, ok := players[name]
+		if !ok {
+			p = &Player{Name: name, Rating: opts.InitialRating}
+			if in, ok := initial[name]; ok {
+				p.Rating = in.Rating
+				p.Games = in.Games
+			}
+			p.Peak = p.Rating
+			players[name] = p
+		}
+		return p
+	}
+
+	_, groups := periods(rated, opts.Period)
+	for _, group := range groups {
+		// Ratings and K-factors are fixed at the start of the period
+		start := make(map[*Player]float64)
+		k := make(map[*Player]float64)
+		change := make(map[*Player]float64)
+		played := make(map[*Player]int)
+		last := make(map[*Player]time.Time)
+
+		for _, g := range group {
+			white, black := player(g.white), player(g.black)
+			for _, p := range []*Player{white, black} {
+				if _, ok := start[p]; !ok {
+					start[p] = p.Rating
+					k[p] = opts.K(p)
+				}
+				played[p]++
+				last[p] = g.date
+			}
+
+			expected := ExpectedScore(start[white], start[black], opts.MaxDifference)
+			change[white] += k[white] * (g.score - expected)
+			change[black] += k[black] * ((1 - g.score) - (1 - expected))
+		}
+
+		for p, c := range change {
+			p.Rating += c
+			p.Games += played[p]
+			p.Peak = math.Max(p.Peak, p.Rating)
+			p.History = append(p.History, HistoryEntry{Date: last[p], Rating: p.Rating, Games: p.Games})
+		}
+	}
+
+	return Result{Players: players, Skipped: skipped}
+}
@@ -0,0 +1,185 @@
+package ratings
+
+import (
+	"math"
+	"time"
+
+	"github.com/schafer14/go-chess/pgn"
+)
+
+// glickoScale converts between the Glicko and Glicko-2 rating scales
+const glickoScale = 173.7178
+
+// GlickoOptions configure a Glicko-2 rating run
+type GlickoOptions struct {
+	InitialRating     float64
+	InitialRD         float64
+	InitialVolatility float64
+	// Tau constrains how much volatility can change between periods. Glickman suggests values
+	// between 0.3 and 1.2.
+	Tau    float64
+	Period Period
+}
+
+// DefaultGlicko are the values suggested by Glickman with monthly rating periods
+var DefaultGlicko = GlickoOptions{
+	InitialRating:     1500,
+	InitialRD:         350,
+	InitialVolatility: 0.06,
+	Tau:               0.5,
+	Period:            Monthly,
+}
+
+// glickoResult is a single game from one player's point of view on the Glicko-2 scale
+type glickoResult struct {
+	mu, phi float64
+	score   float64
+}
+
+// Glicko2 computes Glicko-2 ratings. initial may be nil. Players who don't play in a rating
+// period, including periods in which nobody played, have their RD increased.
+func Glicko2(games []pgn.Game, opts GlickoOptions, initial map[string]Initial) Result {
+	rated, skipped := chronological(games)
+	players := make(map[string]*Player)
+	player := func(name string) *Player {
+		p, ok := players[name]
+		if !ok {
+			p = &Player{Name: name, Rating: opts.InitialRating, RD: opts.InitialRD, Volatility: opts.InitialVolatility}
+			if in, ok := initial[name]; ok {
+				p.Rating = in.Rating
+				p.Games = in.Games
+				if in.RD > 0 {
+					p.RD = in.RD
+				}
+				if in.Volatility > 0 {
+					p.Volatility = in.Volatility
+				}
+			}
+			p.Peak = p.Rating
+			players[name] = p
+		}
+		return p
+	}
+
+	indexes, groups := periods(rated, opts.Period)
+	for i, group := range groups {
+		// Inactivity in empty periods since the last one only increases RD
+		if i > 0 {
+			for gap := indexes[i] - indexes[i-1] - 1; gap > 0; gap-- {
+				for _, p := range players {
+					p.RD = inactiveRD(p)
+				}
+			}
+		}
+
+		results := make(map[*Player][]glickoResult)
+		last := make(map[*Player]time.Time)
+		for _, g := range group {
+			white, black := player(g.white), player(g.black)
+			results[white] = append(results[white], glickoResult{mu: mu(black), phi: phi(black), score: g.score})
+			results[black] = append(results[black], glickoResult{mu: mu(white), phi: phi(white), score: 1 - g.score})
+			last[white], last[black] = g.date, g.date
+		}
+
+		// All updates use the ratings from the start of the period
+		type update struct{ rating, rd, volatility float64 }
+		updates := make(map[*Player]update)
+		for _, p := range players {
+			r, ok := results[p]
+			if !ok {
+				updates[p] = update{p.Rating, inactiveRD(p), p.Volatility}
+				continue
+			}
+			rating, rd, volatility := glickoUpdate(p, r, opts.Tau)
+			updates[p] = update{rating, rd, volatility}
+		}
+
+		for p, u := range updates {
+			p.Rating, p.RD, p.Volatility = u.rating, u.rd, u.volatility
+			if r, ok := results[p]; ok {
+				p.Games += len(r)
+				p.Peak = math.Max(p.Peak, p.Rating)
+				p.History = append(p.History, HistoryEntry{Date: last[p], Rating: p.Rating, RD: p.RD, Games: p.Games})
+			}
+		}
+	}
+
+	return Result{Players: players, Skipped: skipped}
+}
+
+func mu(p *Player) float64 {
+	return (p.Rating - 1500) / glickoScale
+}
+
+func phi(p *Player) float64 {
+	return p.RD / glickoScale
+}
+
+func inactiveRD(p *Player) float64 {
+	ph := phi(p)
+	return math.Sqrt(ph*ph+p.Volatility*p.Volatility) * glickoScale
+}
+
+func g(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+func expected(mu, muJ, phiJ float64) float64 {
+	return 1 / (1 + math.Exp(-g(phiJ)*(mu-muJ)))
+}
+
+// glickoUpdate is step 3 to 8 of the Glicko-2 algorithm for a player who played games in the
+// rating period. It returns the new rating, RD and volatility.
+func glickoUpdate(p *Player, results []glickoResult, tau float64) (float64, float64, float64) {
+	m, ph, sigma := mu(p), phi(p), p.Volatility
+
+	var vInv, sum float64
+	for _, r := range results {
+		gj := g(r.phi)
+		e := expected(m, r.mu, r.phi)
+		vInv += gj * gj * e * (1 - e)
+		sum += gj * (r.score - e)
+	}
+	v := 1 / vInv
+	delta := v * sum
+
+	// Find the new volatility with the Illinois algorithm
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := ph*ph + v + ex
+		return ex*(delta*delta-ph*ph-v-ex)/(2*d*d) - (x-a)/(tau*tau)
+	}
+
+	const epsilon = 0.000001
+	A := a
+	var B float64
+	if delta*delta > ph*ph+v {
+		B = math.Log(delta*delta - ph*ph - v)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+		B = a - k*tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+	newSigma := math.Exp(A / 2)
+
+	phiStar := math.Sqrt(ph*ph + newSigma*newSigma)
+	newPhi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	newMu := m + newPhi*newPhi*sum
+
+	return newMu*glickoScale + 1500, newPhi * glickoScale, newSigma
+}
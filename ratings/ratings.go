@@ -0,0 +1,263 @@
+// Package ratings computes Elo and Glicko-2 ratings from collections of games.
+//
+// Games are processed in chronological order using their Date (or UTCDate) and UTCTime tags and
+// grouped into rating periods. Every player keeps a history of their rating at the end of each
+// period they played in.
+package ratings
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/schafer14/go-chess/pgn"
+)
+
+// Period is the length of a rating period. Ratings used to compute expected scores are fixed
+// for the whole period and changes are applied at its end.
+type Period int
+
+const (
+	// PerGame updates ratings after every game
+	PerGame Period = iota
+	// Daily, Weekly and Monthly group games by calendar day, week (starting Monday) and month
+	Daily
+	Weekly
+	Monthly
+)
+
+// Player is a rated player
+type Player struct {
+	Name   string
+	Rating float64
+	// RD and Volatility are only used by Glicko-2
+	RD         float64
+	Volatility float64
+	// Games is the number of rated games played including games from an imported rating
+	Games int
+	// Peak is the highest rating the player has had
+	Peak    float64
+	History []HistoryEntry
+}
+
+// HistoryEntry is a player's rating at the end of a rating period
+type HistoryEntry struct {
+	// Date is the date of the last game the player played in the period
+	Date   time.Time
+	Rating float64
+	RD     float64
+	Games  int
+}
+
+// Initial is an imported starting rating. Zero RD and Volatility use the Glicko-2 defaults.
+type Initial struct {
+	Rating     float64
+	RD         float64
+	Volatility float64
+	Games      int
+}
+
+// ratedGame is a finished game with its date
+type ratedGame struct {
+	date  time.Time
+	white string
+	black string
+	// score is white's score
+	score float64
+}
+
+// Result is the outcome of a rating run
+type Result struct {
+	Players map[string]*Player
+	// Skipped is the number of games left out because they had no result, no players or no
+	// usable date
+	Skipped int
+}
+
+// chronological returns the finished games with a date in the order they were played. Games
+// on the same date without a UTCTime tag keep their input order.
+func chronological(games []pgn.Game) ([]ratedGame, int) {
+	var rated []ratedGame
+	var skipped int
+	for _, g := range games {
+		var score float64
+		switch g.Tags["Result"] {
+		case "1-0":
+			score = 1
+		case "0-1":
+			score = 0
+		case "1/2-1/2":
+			score = 0.5
+		default:
+			skipped++
+			continue
+		}
+
+		white, black := g.Tags["White"], g.Tags["Black"]
+		date, ok := gameTime(g.Tags)
+		if !ok || unknownName(white) || unknownName(black) {
+			skipped++
+			continue
+		}
+
+		rated = append(rated, ratedGame{date: date, white: white, black: black, score: score})
+	}
+
+	sort.SliceStable(rated, func(i, j int) bool {
+		return rated[i].date.Before(rated[j].date)
+	})
+
+	return rated, skipped
+}
+
+// gameTime returns when a game was played from its Date or UTCDate and UTCTime tags. Unknown
+// months and days are treated as the first of the month or year.
+func gameTime(tags map[string]string) (time.Time, bool) {
+	for _, key := range []string{"Date", "UTCDate"} {
+		parts := strings.Split(tags[key], ".")
+		if len(parts) != 3 {
+			continue
+		}
+		year, err := strconv.Atoi(parts[0])
+		if err != nil {
+			continue
+		}
+		month, err := strconv.Atoi(parts[1])
+		if err != nil || month < 1 || month > 12 {
+			month = 1
+		}
+		day, err := strconv.Atoi(parts[2])
+		if err != nil || day < 1 || day > 31 {
+			day = 1
+		}
+
+		var hour, min, sec int
+		if clock := strings.Split(tags["UTCTime"], ":"); len(clock) == 3 {
+			hour, _ = strconv.Atoi(clock[0])
+			min, _ = strconv.Atoi(clock[1])
+			sec, _ = strconv.Atoi(clock[2])
+		}
+
+		return time.Date(year, time.Month(month), day, hour, min, sec, 0, time.UTC), true
+	}
+
+	return time.Time{}, false
+}
+
+func unknownName(name string) bool {
+	return name == "" || name == "?" || name == "-"
+}
+
+// periods splits games into rating periods. Each period is returned with its index so periods
+// without games between two others can be accounted for.
+func periods(games []ratedGame, period Period) (indexes []int64, groups [][]ratedGame) {
+	for i, g := range games {
+		var index int64
+		switch period {
+		case PerGame:
+			index = int64(i)
+		case Daily:
+			index = days(g.date)
+		case Weekly:
+			// The Unix epoch was a Thursday, shift so weeks start on Monday
+			index = floorDiv(days(g.date)+3, 7)
+		case Monthly:
+			index = int64(g.date.Year())*12 + int64(g.date.Month())
+		}
+
+		if len(indexes) == 0 || indexes[len(indexes)-1] != index {
+			indexes = append(indexes, index)
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], g)
+	}
+	return indexes, groups
+}
+
+func days(t time.Time) int64 {
+	return floorDiv(t.Unix(), 24*60*60)
+}
+
+// floorDiv divides rounding down so times before 1970 fall in the right period
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// ReadInitial reads starting ratings from CSV records of the form
+//
+//	name,rating[,games[,rd[,volatility]]]
+//
+// A first line starting with "name" is treated as a header.
+func ReadInitial(r io.Reader) (map[string]Initial, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	initial := make(map[string]Initial)
+	for line := 1; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return initial, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == 1 && strings.EqualFold(record[0], "name") {
+			continue
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("line %v: expecting at least a name and a rating", line)
+		}
+
+		var in Initial
+		values := []*float64{&in.Rating, nil, &in.RD, &in.Volatility}
+		for i, field := range record[1:] {
+			if i >= len(values) || field == "" {
+				continue
+			}
+			if i == 1 {
+				in.Games, err = strconv.Atoi(field)
+			} else {
+				*values[i], err = strconv.ParseFloat(field, 64)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("line %v: %v", line, err)
+			}
+		}
+		initial[record[0]] = in
+	}
+}
+
+// WriteHistory writes the rating history of every player as CSV records of the form
+// name,date,rating,rd,games sorted by name and date.
+func WriteHistory(w io.Writer, players map[string]*Player) error {
+	var names []string
+	for name := range players {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"name", "date", "rating", "rd", "games"})
+	for _, name := range names {
+		for _, h := range players[name].History {
+			cw.Write([]string{
+				name,
+				h.Date.Format("2006.01.02"),
+				strconv.FormatFloat(h.Rating, 'f', 1, 64),
+				strconv.FormatFloat(h.RD, 'f', 1, 64),
+				strconv.Itoa(h.Games),
+			})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,205 @@
+package ratings
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/schafer14/go-chess/internal/pgntest"
+	"github.com/schafer14/go-chess/pgn"
+)
+
+func near(got, want, tolerance float64) bool {
+	return math.Abs(got-want) <= tolerance
+}
+
+func TestEloPerGame(t *testing.T) {
+	games := []pgn.Game{
+		// Out of order on purpose, the second game is played first
+		pgntest.Game("", "2019.01.02", "Alice", "Bob", "1/2-1/2"),
+		pgntest.Game("", "2019.01.01", "Alice", "Bob", "1-0"),
+		pgntest.Game("", "2019.01.03", "Alice", "Carol", "*"),
+		pgntest.Game("", "????.??.??", "Alice", "Carol", "1-0"),
+	}
+	opts := FIDE
+	opts.Period = PerGame
+
+	result := Elo(games, opts, nil)
+	if result.Skipped != 2 {
+		t.Errorf("Skipped = %v, want 2", result.Skipped)
+	}
+
+	alice := result.Players["Alice"]
+	// 1500 + 40 * 0.5 = 1520, then a draw against a 1480: 1520 + 40 * (0.5 - 0.5575)
+	if !near(alice.Rating, 1517.70, 0.01) {
+		t.Errorf("Alice rating = %v, want 1517.70", alice.Rating)
+	}
+	if len(alice.History) != 2 || alice.History[0].Rating != 1520 || alice.History[0].Date.Day() != 1 {
+		t.Errorf("Alice history = %+v", alice.History)
+	}
+	if alice.Games != 2 || alice.Peak != 1520 {
+		t.Errorf("Alice games = %v peak = %v, want 2 and 1520", alice.Games, alice.Peak)
+	}
+	if !near(result.Players["Bob"].Rating, 1482.30, 0.01) {
+		t.Errorf("Bob rating = %v, want 1482.30", result.Players["Bob"].Rating)
+	}
+}
+
+func TestEloFIDE(t *testing.T) {
+	games := []pgn.Game{
+		pgntest.Game("", "2019.01.05", "Master", "Novice", "1-0"),
+		pgntest.Game("", "2019.01.20", "Master", "Novice", "1-0"),
+	}
+	initial := map[string]Initial{
+		"Master": {Rating: 2450, Games: 500},
+		"Novice": {Rating: 1700, Games: 40},
+	}
+
+	result := Elo(games, FIDE, initial)
+
+	// Both games use the ratings from the start of the month and the difference is capped at
+	// 400, so the master gains 2 * 10 * (1 - 0.909)
+	master := result.Players["Master"]
+	if !near(master.Rating, 2451.82, 0.01) {
+		t.Errorf("Master rating = %v, want 2451.82", master.Rating)
+	}
+	if len(master.History) != 1 || master.Games != 502 {
+		t.Errorf("Master history = %+v games = %v", master.History, master.Games)
+	}
+	if !near(result.Players["Novice"].Rating, 1696.36, 0.01) {
+		t.Errorf("Novice rating = %v, want 1696.36", result.Players["Novice"].Rating)
+	}
+}
+
+func TestFIDEK(t *testing.T) {
+	tests := []struct {
+		player Player
+		want   float64
+	}{
+		{Player{Rating: 1500, Games: 5}, 40},
+		{Player{Rating: 1500, Peak: 1500, Games: 30}, 20},
+		{Player{Rating: 2350, Peak: 2410, Games: 300}, 10},
+	}
+	for _, tt := range tests {
+		if got := FIDEK(&tt.player); got != tt.want {
+			t.Errorf("FIDEK(%+v) = %v, want %v", tt.player, got, tt.want)
+		}
+	}
+}
+
+// TestGlicko2 uses the worked example from Glickman's description of the Glicko-2 system
+func TestGlicko2(t *testing.T) {
+	games := []pgn.Game{
+		pgntest.Game("", "2019.01.01", "Player", "A", "1-0"),
+		pgntest.Game("", "2019.01.02", "B", "Player", "1-0"),
+		pgntest.Game("", "2019.01.03", "Player", "C", "0-1"),
+	}
+	initial := map[string]Initial{
+		"Player": {Rating: 1500, RD: 200},
+		"A":      {Rating: 1400, RD: 30},
+		"B":      {Rating: 1550, RD: 100},
+		"C":      {Rating: 1700, RD: 300},
+	}
+
+	result := Glicko2(games, DefaultGlicko, initial)
+	p := result.Players["Player"]
+	if !near(p.Rating, 1464.06, 0.01) || !near(p.RD, 151.52, 0.01) || !near(p.Volatility, 0.05999, 0.00001) {
+		t.Errorf("Player = %v RD %v volatility %v, want 1464.06 RD 151.52 volatility 0.05999", p.Rating, p.RD, p.Volatility)
+	}
+	if len(p.History) != 1 || p.Games != 3 {
+		t.Errorf("Player history = %+v games = %v", p.History, p.Games)
+	}
+}
+
+func TestGlicko2Inactivity(t *testing.T) {
+	games := []pgn.Game{
+		pgntest.Game("", "2019.01.01", "Alice", "Bob", "1-0"),
+		pgntest.Game("", "2019.04.01", "Alice", "Carol", "1-0"),
+	}
+
+	result := Glicko2(games, DefaultGlicko, nil)
+	bob := result.Players["Bob"]
+	// Bob's RD grows over February, March and April
+	want := bob.History[0].RD
+	for i := 0; i < 3; i++ {
+		want = math.Sqrt(want*want + math.Pow(bob.Volatility*glickoScale, 2))
+	}
+	if !near(bob.RD, want, 0.001) {
+		t.Errorf("Bob RD = %v, want %v", bob.RD, want)
+	}
+	if len(result.Players["Alice"].History) != 2 {
+		t.Errorf("Alice history = %+v", result.Players["Alice"].History)
+	}
+}
+
+func TestPeriods_before1970(t *testing.T) {
+	at := func(s string) ratedGame {
+		d, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ratedGame{date: d}
+	}
+
+	tests := []struct {
+		name   string
+		period Period
+		games  []ratedGame
+		want   int
+	}{
+		// Tuesday and Sunday of one week and the Sunday after it
+		{"weekly", Weekly, []ratedGame{at("1969-12-23 12:00"), at("1969-12-28 12:00"), at("1970-01-04 12:00")}, 2},
+		{"daily", Daily, []ratedGame{at("1969-12-31 01:00"), at("1969-12-31 23:00"), at("1970-01-01 01:00")}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, groups := periods(tt.games, tt.period); len(groups) != tt.want {
+				t.Errorf("periods() = %v periods, want %v", len(groups), tt.want)
+			}
+		})
+	}
+}
+
+func TestReadInitial(t *testing.T) {
+	input := `name,rating,games,rd,volatility
+Alice,1800,25
+"Carlsen, Magnus",2850,3000,50,0.05
+Bob,1400,,120
+`
+	got, err := ReadInitial(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ReadInitial() error = %v", err)
+	}
+	if got["Alice"] != (Initial{Rating: 1800, Games: 25}) {
+		t.Errorf("Alice = %+v", got["Alice"])
+	}
+	if got["Carlsen, Magnus"] != (Initial{Rating: 2850, Games: 3000, RD: 50, Volatility: 0.05}) {
+		t.Errorf("Carlsen = %+v", got["Carlsen, Magnus"])
+	}
+	if got["Bob"] != (Initial{Rating: 1400, RD: 120}) {
+		t.Errorf("Bob = %+v", got["Bob"])
+	}
+
+	if _, err := ReadInitial(strings.NewReader("Alice,strong\n")); err == nil {
+		t.Errorf("ReadInitial() expected an error for a rating that isn't a number")
+	}
+}
+
+func TestWriteHistory(t *testing.T) {
+	opts := FIDE
+	opts.Period = PerGame
+	result := Elo([]pgn.Game{pgntest.Game("", "2019.01.01", "Bob", "Alice", "0-1")}, opts, nil)
+
+	var sb strings.Builder
+	if err := WriteHistory(&sb, result.Players); err != nil {
+		t.Fatalf("WriteHistory() error = %v", err)
+	}
+	want := `name,date,rating,rd,games
+Alice,2019.01.01,1520.0,0.0,1
+Bob,2019.01.01,1480.0,0.0,1
+`
+	if sb.String() != want {
+		t.Errorf("WriteHistory() =\n%v\nwant\n%v", sb.String(), want)
+	}
+}
@@ -0,0 +1,314 @@
+// Package stats computes player statistics from collections of games using the White, Black,
+// WhiteElo, BlackElo, Result, Date, ECO and TimeControl tags.
+package stats
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/schafer14/go-chess/pgn"
+)
+
+// Record is a count of results
+type Record struct {
+	Games  int
+	Wins   int
+	Draws  int
+	Losses int
+}
+
+// Score returns the number of points scored counting a draw as half a point
+func (r Record) Score() float64 {
+	return float64(r.Wins) + float64(r.Draws)/2
+}
+
+// Percent returns the score as a percentage of the games played
+func (r Record) Percent() float64 {
+	if r.Games == 0 {
+		return 0
+	}
+	return 100 * r.Score() / float64(r.Games)
+}
+
+func (r *Record) add(score float64) {
+	r.Games++
+	switch score {
+	case 1:
+		r.Wins++
+	case 0:
+		r.Losses++
+	default:
+		r.Draws++
+	}
+}
+
+// Streak is a run of consecutive results
+type Streak struct {
+	// Result is W, D or L
+	Result string
+	Length int
+}
+
+// Player are the statistics of a single player
+type Player struct {
+	Name string
+	Record
+	White Record
+	Black Record
+	// ByECO and ByTimeControl split the results by the ECO and TimeControl tags. Games without
+	// the tag are counted under "?".
+	ByECO         map[string]Record
+	ByTimeControl map[string]Record
+	// RatedGames is the number of games where the opponent has an Elo rating
+	RatedGames         int
+	AverageOpponentElo int
+	// Performance is the FIDE performance rating over the rated games
+	Performance int
+	// LongestWinStreak, LongestUnbeaten and LongestLosingStreak are the longest runs of games
+	// in date order
+	LongestWinStreak    int
+	LongestUnbeaten     int
+	LongestLosingStreak int
+	CurrentStreak       Streak
+	// FirstGame and LastGame are the dates of the earliest and latest games with a known date
+	FirstGame string
+	LastGame  string
+}
+
+// result is one finished game from a player's point of view
+type result struct {
+	date        string
+	white       bool
+	score       float64
+	opponentElo int
+	eco         string
+	timeControl string
+}
+
+// ForPlayer computes the statistics of one player. Names are compared ignoring case. Games
+// without a result are ignored.
+func ForPlayer(games []pgn.Game, name string) Player {
+	var results []result
+	for _, g := range games {
+		if r, ok := resultFor(g, name); ok {
+			results = append(results, r)
+		}
+	}
+	return compute(name, results)
+}
+
+// All computes the statistics of every player in a collection keyed by name. Names that only
+// differ in case are treated as the same player and keyed by the first spelling found.
+func All(games []pgn.Game) map[string]Player {
+	results := make(map[string][]result)
+	names := make(map[string]string)
+	for _, g := range games {
+		for _, side := range []string{"White", "Black"} {
+			name := g.Tags[side]
+			if name == "" || name == "?" {
+				continue
+			}
+			if first, ok := names[strings.ToLower(name)]; ok {
+				name = first
+			} else {
+				names[strings.ToLower(name)] = name
+			}
+			if r, ok := resultFor(g, name); ok {
+				results[name] = append(results[name], r)
+			}
+		}
+	}
+
+	players := make(map[string]Player, len(results))
+	for name, r := range results {
+		players[name] = compute(name, r)
+	}
+	return players
+}
+
+func resultFor(g pgn.Game, name string) (result, bool) {
+	var r result
+	switch {
+	case strings.EqualFold(g.Tags["White"], name):
+		r.white = true
+	case strings.EqualFold(g.Tags["Black"], name):
+	default:
+		return r, false
+	}
+
+	switch g.Tags["Result"] {
+	case "1-0":
+		r.score = 1
+	case "0-1":
+		r.score = 0
+	case "1/2-1/2":
+		r.score = 0.5
+	default:
+		return r, false
+	}
+	if !r.white {
+		r.score = 1 - r.score
+	}
+
+	opponentElo := g.Tags["BlackElo"]
+	if !r.white {
+		opponentElo = g.Tags["WhiteElo"]
+	}
+	r.opponentElo, _ = strconv.Atoi(opponentElo)
+
+	r.date = Date(g.Tags)
+	r.eco = tagOrUnknown(g.Tags["ECO"])
+	r.timeControl = tagOrUnknown(g.Tags["TimeControl"])
+
+	return r, true
+}
+
+func compute(name string, results []result) Player {
+	p := Player{
+		Name:          name,
+		ByECO:         make(map[string]Record),
+		ByTimeControl: make(map[string]Record),
+	}
+
+	// Games without a date sort first and otherwise keep the order they were given in
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].date < results[j].date
+	})
+
+	var opponentElo int
+	var ratedScore float64
+	var win, unbeaten, loss int
+	for _, r := range results {
+		p.Record.add(r.score)
+		if r.white {
+			p.White.add(r.score)
+		} else {
+			p.Black.add(r.score)
+		}
+
+		eco := p.ByECO[r.eco]
+		eco.add(r.score)
+		p.ByECO[r.eco] = eco
+		tc := p.ByTimeControl[r.timeControl]
+		tc.add(r.score)
+		p.ByTimeControl[r.timeControl] = tc
+
+		if r.opponentElo > 0 {
+			p.RatedGames++
+			opponentElo += r.opponentElo
+			ratedScore += r.score
+		}
+
+		if r.date != "" {
+			if p.FirstGame == "" {
+				p.FirstGame = r.date
+			}
+			p.LastGame = r.date
+		}
+
+		win, unbeaten, loss = next(win, r.score == 1), next(unbeaten, r.score > 0), next(loss, r.score == 0)
+		p.LongestWinStreak = maxInt(p.LongestWinStreak, win)
+		p.LongestUnbeaten = maxInt(p.LongestUnbeaten, unbeaten)
+		p.LongestLosingStreak = maxInt(p.LongestLosingStreak, loss)
+
+		letter := resultLetter(r.score)
+		if p.CurrentStreak.Result == letter {
+			p.CurrentStreak.Length++
+		} else {
+			p.CurrentStreak = Streak{Result: letter, Length: 1}
+		}
+	}
+
+	if p.RatedGames > 0 {
+		p.AverageOpponentElo = round(float64(opponentElo) / float64(p.RatedGames))
+		p.Performance = PerformanceRating(float64(opponentElo)/float64(p.RatedGames), ratedScore, p.RatedGames)
+	}
+
+	return p
+}
+
+func next(streak int, extends bool) int {
+	if extends {
+		return streak + 1
+	}
+	return 0
+}
+
+func resultLetter(score float64) string {
+	switch score {
+	case 1:
+		return "W"
+	case 0:
+		return "L"
+	}
+	return "D"
+}
+
+// dp is the FIDE table of rating differences by percentage score from 50% to 100%
+var dp = [51]int{
+	0, 7, 14, 21, 29, 36, 43, 50, 57, 65,
+	72, 80, 87, 95, 102, 110, 117, 125, 133, 141,
+	149, 158, 166, 175, 184, 193, 202, 211, 220, 230,
+	240, 251, 262, 273, 284, 296, 309, 322, 336, 351,
+	366, 383, 401, 422, 444, 470, 501, 538, 589, 677,
+	800,
+}
+
+// RatingDifference returns the FIDE rating difference for a fractional score p between 0 and 1.
+// p is rounded to the nearest percent.
+func RatingDifference(p float64) int {
+	percent := round(p * 100)
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 100 {
+		percent = 100
+	}
+	if percent >= 50 {
+		return dp[percent-50]
+	}
+	return -dp[50-percent]
+}
+
+// PerformanceRating returns the FIDE performance rating of a player who scored score points
+// in games against opponents with an average rating of averageOpponent.
+func PerformanceRating(averageOpponent, score float64, games int) int {
+	if games == 0 {
+		return 0
+	}
+	return round(averageOpponent) + RatingDifference(score/float64(games))
+}
+
+// Date returns the Date tag of a game falling back to UTCDate. Incomplete dates such as
+// 2019.??.?? are returned as they are, completely unknown dates as an empty string.
+func Date(tags map[string]string) string {
+	for _, key := range []string{"Date", "UTCDate"} {
+		d := tags[key]
+		if d != "" && strings.Trim(d, "?.") != "" {
+			return d
+		}
+	}
+	return ""
+}
+
+func tagOrUnknown(v string) string {
+	if v == "" {
+		return "?"
+	}
+	return v
+}
+
+func round(f float64) int {
+	if f < 0 {
+		return int(f - 0.5)
+	}
+	return int(f + 0.5)
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
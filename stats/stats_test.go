@@ -0,0 +1,143 @@
+package stats
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/schafer14/go-chess/pgn"
+)
+
+const games = `[Date "2019.01.03"]
+[White "Alice"]
+[Black "Bob"]
+[Result "1-0"]
+[WhiteElo "1500"]
+[BlackElo "1600"]
+[ECO "C20"]
+[TimeControl "300+3"]
+
+1. e4 e5 1-0
+
+[Date "2019.01.01"]
+[White "Bob"]
+[Black "alice"]
+[Result "1-0"]
+[WhiteElo "1600"]
+[BlackElo "1500"]
+[ECO "B20"]
+[TimeControl "300+3"]
+
+1. e4 c5 1-0
+
+[Date "2019.01.02"]
+[White "Carol"]
+[Black "Alice"]
+[Result "0-1"]
+[WhiteElo "1400"]
+[BlackElo "1510"]
+[ECO "A00"]
+[TimeControl "600+0"]
+
+1. a3 e5 0-1
+
+[Date "2019.01.04"]
+[White "Alice"]
+[Black "Carol"]
+[Result "1/2-1/2"]
+[ECO "C20"]
+
+1. e4 e5 1/2-1/2
+
+[Date "2019.01.05"]
+[White "Alice"]
+[Black "Dave"]
+[Result "*"]
+
+1. e4 *
+`
+
+func parse(t *testing.T) []pgn.Game {
+	parsed, err := pgn.Parse(strings.NewReader(games))
+	if err != nil {
+		t.Fatalf("Could not parse test games: %v", err)
+	}
+	return parsed
+}
+
+func TestForPlayer(t *testing.T) {
+	p := ForPlayer(parse(t), "Alice")
+
+	if p.Record != (Record{Games: 4, Wins: 2, Draws: 1, Losses: 1}) {
+		t.Errorf("Record = %+v", p.Record)
+	}
+	if p.Score() != 2.5 || p.Percent() != 62.5 {
+		t.Errorf("Score() = %v Percent() = %v, want 2.5 and 62.5", p.Score(), p.Percent())
+	}
+	if p.White != (Record{Games: 2, Wins: 1, Draws: 1}) {
+		t.Errorf("White = %+v", p.White)
+	}
+	if p.Black != (Record{Games: 2, Wins: 1, Losses: 1}) {
+		t.Errorf("Black = %+v", p.Black)
+	}
+	if p.ByECO["C20"] != (Record{Games: 2, Wins: 1, Draws: 1}) {
+		t.Errorf("ByECO[C20] = %+v", p.ByECO["C20"])
+	}
+	if p.ByTimeControl["?"].Games != 1 || p.ByTimeControl["300+3"].Games != 2 {
+		t.Errorf("ByTimeControl = %+v", p.ByTimeControl)
+	}
+
+	// Rated games against 1600, 1600 and 1400 scoring 2 out of 3
+	if p.RatedGames != 3 || p.AverageOpponentElo != 1533 {
+		t.Errorf("RatedGames = %v AverageOpponentElo = %v, want 3 and 1533", p.RatedGames, p.AverageOpponentElo)
+	}
+	if p.Performance != 1533+125 {
+		t.Errorf("Performance = %v, want %v", p.Performance, 1533+125)
+	}
+
+	// In date order the results are L W W D
+	if p.LongestWinStreak != 2 || p.LongestUnbeaten != 3 || p.LongestLosingStreak != 1 {
+		t.Errorf("Streaks = %v %v %v, want 2 3 1", p.LongestWinStreak, p.LongestUnbeaten, p.LongestLosingStreak)
+	}
+	if p.CurrentStreak != (Streak{Result: "D", Length: 1}) {
+		t.Errorf("CurrentStreak = %+v", p.CurrentStreak)
+	}
+	if p.FirstGame != "2019.01.01" || p.LastGame != "2019.01.04" {
+		t.Errorf("FirstGame = %v LastGame = %v", p.FirstGame, p.LastGame)
+	}
+}
+
+func TestAll(t *testing.T) {
+	players := All(parse(t))
+
+	if len(players) != 3 {
+		t.Errorf("All() returned %v players, want 3", len(players))
+	}
+	if players["Alice"].Games != 4 {
+		t.Errorf("Alice played %v games, want 4", players["Alice"].Games)
+	}
+	if players["Carol"].Games != 2 || players["Carol"].Score() != 0.5 {
+		t.Errorf("Carol = %+v", players["Carol"].Record)
+	}
+	if players["Bob"].Record != (Record{Games: 2, Wins: 1, Losses: 1}) {
+		t.Errorf("Bob = %+v", players["Bob"].Record)
+	}
+}
+
+func TestRatingDifference(t *testing.T) {
+	tests := []struct {
+		p    float64
+		want int
+	}{
+		{0.5, 0},
+		{0.67, 125},
+		{0.666, 125},
+		{1, 800},
+		{0, -800},
+		{0.25, -193},
+	}
+	for _, tt := range tests {
+		if got := RatingDifference(tt.p); got != tt.want {
+			t.Errorf("RatingDifference(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package timecontrol
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/schafer14/go-chess/pgn"
+)
+
+// ErrNoClock is returned when simulating a clock for an unknown or unlimited time control
+var ErrNoClock = errors.New("time control has no clock")
+
+// Clock is a chess clock advanced one move at a time, starting with white
+type Clock struct {
+	tc        TimeControl
+	remaining [2]time.Duration
+	// stage is the index of each player's current stage and stageMoves the moves they have
+	// made in it
+	stage      [2]int
+	stageMoves [2]int
+	flagged    [2]bool
+	turn       int
+}
+
+// NewClock returns a clock at the start of a game
+func NewClock(tc TimeControl) (*Clock, error) {
+	if (tc.Kind != Normal && tc.Kind != Sandclock) || len(tc.Stages) == 0 {
+		return nil, ErrNoClock
+	}
+	c := &Clock{tc: tc}
+	c.remaining[0] = tc.Stages[0].Time
+	c.remaining[1] = tc.Stages[0].Time
+	return c, nil
+}
+
+// WhiteToMove reports whether the clock is running for white
+func (c *Clock) WhiteToMove() bool {
+	return c.turn == 0
+}
+
+// Remaining returns a player's time left
+func (c *Clock) Remaining(white bool) time.Duration {
+	return c.remaining[side(white)]
+}
+
+// Flagged reports whether a player has run out of time
+func (c *Clock) Flagged(white bool) bool {
+	return c.flagged[side(white)]
+}
+
+func side(white bool) int {
+	if white {
+		return 0
+	}
+	return 1
+}
+
+func (c *Clock) currentStage(s int) Stage {
+	if c.stage[s] < len(c.tc.Stages) {
+		return c.tc.Stages[c.stage[s]]
+	}
+	return c.tc.Stages[len(c.tc.Stages)-1]
+}
+
+// Move makes a move that took elapsed and returns the mover's time left after it, including
+// any bonus and the time of a new stage
+func (c *Clock) Move(elapsed time.Duration) time.Duration {
+	s := c.turn
+	stage := c.currentStage(s)
+
+	used := elapsed
+	if c.tc.Kind == Normal && stage.BonusKind == Delay {
+		used -= stage.Bonus
+		if used < 0 {
+			used = 0
+		}
+	}
+	c.remaining[s] -= used
+	if c.tc.Kind == Sandclock {
+		c.remaining[1-s] += used
+	}
+
+	if c.remaining[s] <= 0 && used > 0 {
+		c.flagged[s] = true
+	} else if c.tc.Kind == Normal {
+		switch stage.BonusKind {
+		case Increment:
+			c.remaining[s] += stage.Bonus
+		case Bronstein:
+			if elapsed < stage.Bonus {
+				c.remaining[s] += elapsed
+			} else {
+				c.remaining[s] += stage.Bonus
+			}
+		}
+	}
+
+	c.endMove(s, true)
+	return c.remaining[s]
+}
+
+// MoveTo makes a move after which the mover had remaining time left, as given by a [%clk]
+// command, and returns how long the move took. With a Bronstein or US delay, moves that took
+// less than the delay can't be told apart and are returned as taking no time.
+func (c *Clock) MoveTo(remaining time.Duration) time.Duration {
+	s := c.turn
+	stage := c.currentStage(s)
+	before := c.remaining[s]
+
+	// The time of the next stage is already included in remaining
+	index := c.stage[s]
+	c.endMove(s, false)
+	if c.stage[s] != index {
+		before += c.currentStage(s).Time
+	}
+
+	elapsed := before - remaining
+	if c.tc.Kind == Normal && remaining > 0 && stage.Bonus > 0 {
+		switch stage.BonusKind {
+		case Increment:
+			elapsed += stage.Bonus
+		case Bronstein, Delay:
+			if elapsed > 0 {
+				elapsed += stage.Bonus
+			}
+		}
+	}
+	if elapsed < 0 {
+		elapsed = 0
+	}
+
+	if c.tc.Kind == Sandclock {
+		c.remaining[1-s] += c.remaining[s] - remaining
+	}
+	c.remaining[s] = remaining
+	if remaining <= 0 {
+		c.flagged[s] = true
+	}
+	return elapsed
+}
+
+// endMove counts the move and moves the player on to the next stage when the current one is
+// complete, adding its time when addTime is set
+func (c *Clock) endMove(s int, addTime bool) {
+	stage := c.currentStage(s)
+	c.stageMoves[s]++
+	if stage.Moves > 0 && c.stageMoves[s] == stage.Moves {
+		c.stage[s]++
+		c.stageMoves[s] = 0
+		if addTime {
+			c.remaining[s] += c.currentStage(s).Time
+		}
+	}
+	c.turn = 1 - s
+}
+
+// MoveTime is the time a move took and the mover's time left after it
+type MoveTime struct {
+	Elapsed   time.Duration
+	Remaining time.Duration
+}
+
+// Reconstruct replays the clocks of a game's main line. Moves use their [%clk] when they have
+// one and otherwise their [%emt] or [%timestamp]. The times up to the first move with none of
+// them are returned along with an error.
+func Reconstruct(tc TimeControl, moves []pgn.Move) ([]MoveTime, error) {
+	c, err := NewClock(tc)
+	if err != nil {
+		return nil, err
+	}
+
+	var times []MoveTime
+	for i, m := range moves {
+		var t MoveTime
+		switch {
+		case m.Clock != nil:
+			t.Remaining = *m.Clock
+			t.Elapsed = c.MoveTo(*m.Clock)
+		case m.Elapsed != nil:
+			t.Elapsed = *m.Elapsed
+			t.Remaining = c.Move(*m.Elapsed)
+		case m.Timestamp != nil:
+			t.Elapsed = *m.Timestamp
+			t.Remaining = c.Move(*m.Timestamp)
+		default:
+			return times, fmt.Errorf("move %v has no clock or elapsed time", i+1)
+		}
+		times = append(times, t)
+	}
+	return times, nil
+}
+
+// Forfeit reports whether a decisive game was lost on time and whether white was the player
+// who lost. A game is a time forfeit when its Termination tag says so, as lichess and
+// chess.com write it, or when the loser's clock reached zero replaying the game.
+func Forfeit(g pgn.Game) (forfeit bool, white bool) {
+	switch g.Tags["Result"] {
+	case "1-0":
+		white = false
+	case "0-1":
+		white = true
+	default:
+		return false, false
+	}
+
+	termination := strings.ToLower(g.Tags["Termination"])
+	if strings.Contains(termination, "time forfeit") || strings.Contains(termination, "on time") {
+		return true, white
+	}
+
+	tc, err := Parse(g.Tags["TimeControl"])
+	if err != nil {
+		return false, white
+	}
+	// Partial replays still show a flag before the first move without a time
+	times, _ := Reconstruct(tc, g.Moves)
+	for i, t := range times {
+		if (i%2 == 0) == white && t.Remaining <= 0 {
+			return true, white
+		}
+	}
+	return false, white
+}
@@ -0,0 +1,175 @@
+// Package timecontrol reads pgn TimeControl tags and simulates chess clocks.
+//
+// A TimeControl tag is a list of stages separated by colons. Each stage is the number of
+// seconds for the stage, optionally prefixed by the number of moves in it and followed by a
+// bonus, such as 40/7200:3600 or 600+8. The tag may also be ? for unknown, - for no time
+// control or *60 for a sandclock. As the pgn standard has no syntax for delays, d and b are
+// accepted in place of + for a US delay and a Bronstein delay, as in 5400d30 or 5400b30.
+package timecontrol
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the type of time control
+type Kind int
+
+const (
+	// Unknown is a missing TimeControl tag or ?
+	Unknown Kind = iota
+	// Unlimited is a game without a time control, written as -
+	Unlimited
+	// Normal is a time control made of one or more stages
+	Normal
+	// Sandclock is a sandclock or hourglass where the time one player uses is added to the
+	// other's
+	Sandclock
+)
+
+// Bonus is how extra time is given for each move
+type Bonus int
+
+const (
+	// Increment adds the bonus after every move
+	Increment Bonus = iota
+	// Bronstein adds back the time used on a move up to the bonus
+	Bronstein
+	// Delay waits for the bonus before the clock starts running on each move
+	Delay
+)
+
+// bonusSymbols are the separators used for each kind of bonus
+var bonusSymbols = []string{"+", "b", "d"}
+
+// Stage is a period of a time control
+type Stage struct {
+	// Moves is the number of moves each player has to make in the stage, or zero for the rest
+	// of the game. A last stage with moves repeats until the end of the game.
+	Moves int
+	// Time is added to the clock at the start of the stage
+	Time      time.Duration
+	Bonus     time.Duration
+	BonusKind Bonus
+}
+
+// TimeControl is the time control of a game
+type TimeControl struct {
+	Kind Kind
+	// Stages has the stages of a Normal time control and a single stage with the time of a
+	// Sandclock
+	Stages []Stage
+}
+
+// Parse reads the value of a TimeControl tag
+func Parse(tag string) (TimeControl, error) {
+	tag = strings.TrimSpace(tag)
+	switch {
+	case tag == "" || tag == "?":
+		return TimeControl{Kind: Unknown}, nil
+	case tag == "-":
+		return TimeControl{Kind: Unlimited}, nil
+	case strings.HasPrefix(tag, "*"):
+		d, err := seconds(tag[1:])
+		if err != nil || d <= 0 {
+			return TimeControl{}, fmt.Errorf("invalid time control %q", tag)
+		}
+		return TimeControl{Kind: Sandclock, Stages: []Stage{{Time: d}}}, nil
+	}
+
+	tc := TimeControl{Kind: Normal}
+	fields := strings.Split(tag, ":")
+	for i, field := range fields {
+		stage, err := parseStage(field)
+		// Only the last stage may be for the rest of the game
+		if err != nil || (stage.Moves == 0 && i < len(fields)-1) {
+			return TimeControl{}, fmt.Errorf("invalid time control %q", tag)
+		}
+		tc.Stages = append(tc.Stages, stage)
+	}
+	return tc, nil
+}
+
+func parseStage(field string) (Stage, error) {
+	var stage Stage
+	if i := strings.IndexByte(field, '/'); i >= 0 {
+		moves, err := strconv.Atoi(field[:i])
+		if err != nil || moves < 1 {
+			return stage, fmt.Errorf("invalid number of moves %q", field[:i])
+		}
+		stage.Moves = moves
+		field = field[i+1:]
+	}
+
+	if i := strings.IndexAny(field, "+bd"); i >= 0 {
+		bonus, err := seconds(field[i+1:])
+		if err != nil {
+			return stage, err
+		}
+		stage.Bonus = bonus
+		switch field[i] {
+		case 'b':
+			stage.BonusKind = Bronstein
+		case 'd':
+			stage.BonusKind = Delay
+		}
+		field = field[:i]
+	}
+
+	t, err := seconds(field)
+	if err != nil {
+		return stage, err
+	}
+	stage.Time = t
+	return stage, nil
+}
+
+func seconds(s string) (time.Duration, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("invalid number of seconds %q", s)
+	}
+	return time.Duration(f*float64(time.Second) + 0.5), nil
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
+
+// String returns the time control as a TimeControl tag value
+func (tc TimeControl) String() string {
+	switch tc.Kind {
+	case Unlimited:
+		return "-"
+	case Sandclock:
+		if len(tc.Stages) > 0 {
+			return "*" + formatSeconds(tc.Stages[0].Time)
+		}
+	case Normal:
+		var fields []string
+		for _, s := range tc.Stages {
+			field := formatSeconds(s.Time)
+			if s.Moves > 0 {
+				field = strconv.Itoa(s.Moves) + "/" + field
+			}
+			if s.Bonus > 0 {
+				field += bonusSymbols[s.BonusKind] + formatSeconds(s.Bonus)
+			}
+			fields = append(fields, field)
+		}
+		return strings.Join(fields, ":")
+	}
+	return "?"
+}
+
+// Estimated is the expected length of a game for each player: the time of the first stage plus
+// 40 times its bonus. It is the measure lichess uses to tell bullet, blitz, rapid and classical
+// games apart. Unknown and unlimited time controls return zero.
+func (tc TimeControl) Estimated() time.Duration {
+	if len(tc.Stages) == 0 {
+		return 0
+	}
+	return tc.Stages[0].Time + 40*tc.Stages[0].Bonus
+}
@@ -0,0 +1,202 @@
+package timecontrol
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/schafer14/go-chess/pgn"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		tag     string
+		want    TimeControl
+		wantErr bool
+	}{
+		{"?", TimeControl{Kind: Unknown}, false},
+		{"", TimeControl{Kind: Unknown}, false},
+		{"-", TimeControl{Kind: Unlimited}, false},
+		{"*60", TimeControl{Kind: Sandclock, Stages: []Stage{{Time: time.Minute}}}, false},
+		{"600+8", TimeControl{Kind: Normal, Stages: []Stage{{Time: 10 * time.Minute, Bonus: 8 * time.Second}}}, false},
+		{"40/7200:3600", TimeControl{Kind: Normal, Stages: []Stage{{Moves: 40, Time: 2 * time.Hour}, {Time: time.Hour}}}, false},
+		{"40/5400+30:1800+30", TimeControl{Kind: Normal, Stages: []Stage{
+			{Moves: 40, Time: 90 * time.Minute, Bonus: 30 * time.Second},
+			{Time: 30 * time.Minute, Bonus: 30 * time.Second},
+		}}, false},
+		{"5400d30", TimeControl{Kind: Normal, Stages: []Stage{{Time: 90 * time.Minute, Bonus: 30 * time.Second, BonusKind: Delay}}}, false},
+		{"300b2", TimeControl{Kind: Normal, Stages: []Stage{{Time: 5 * time.Minute, Bonus: 2 * time.Second, BonusKind: Bronstein}}}, false},
+		{"40/9000", TimeControl{Kind: Normal, Stages: []Stage{{Moves: 40, Time: 150 * time.Minute}}}, false},
+		{"3600:40/7200", TimeControl{}, true},
+		{"600+", TimeControl{}, true},
+		{"0/600", TimeControl{}, true},
+		{"*", TimeControl{}, true},
+		{"fast", TimeControl{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, err := Parse(tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %+v, want %+v", got, tt.want)
+			}
+			if !tt.wantErr && tt.tag != "" && got.String() != tt.tag {
+				t.Errorf("String() = %v, want %v", got.String(), tt.tag)
+			}
+		})
+	}
+}
+
+func TestTimeControl_Estimated(t *testing.T) {
+	tc, _ := Parse("180+2")
+	if got := tc.Estimated(); got != 260*time.Second {
+		t.Errorf("Estimated() = %v, want %v", got, 260*time.Second)
+	}
+}
+
+func TestClock_Move(t *testing.T) {
+	tests := []struct {
+		name    string
+		tag     string
+		elapsed []time.Duration
+		want    []time.Duration
+	}{
+		{"increment", "60+2", []time.Duration{5 * time.Second, time.Second, 10 * time.Second}, []time.Duration{57 * time.Second, 61 * time.Second, 49 * time.Second}},
+		{"bronstein", "60b2", []time.Duration{5 * time.Second, time.Second}, []time.Duration{57 * time.Second, 60 * time.Second}},
+		{"delay", "60d2", []time.Duration{5 * time.Second, time.Second}, []time.Duration{57 * time.Second, 60 * time.Second}},
+		{"sandclock", "*60", []time.Duration{5 * time.Second, 10 * time.Second, 0}, []time.Duration{55 * time.Second, 55 * time.Second, 65 * time.Second}},
+		{"stages", "2/60:30", []time.Duration{10 * time.Second, 10 * time.Second, 10 * time.Second, 10 * time.Second}, []time.Duration{50 * time.Second, 50 * time.Second, 70 * time.Second, 70 * time.Second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tc, err := Parse(tt.tag)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			c, err := NewClock(tc)
+			if err != nil {
+				t.Fatalf("NewClock() error = %v", err)
+			}
+			var got []time.Duration
+			for _, e := range tt.elapsed {
+				got = append(got, c.Move(e))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Move() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClock_flag(t *testing.T) {
+	tc, _ := Parse("60+1")
+	c, _ := NewClock(tc)
+	c.Move(10 * time.Second)
+	c.Move(61 * time.Second)
+	if c.Flagged(true) || !c.Flagged(false) {
+		t.Errorf("Flagged() = %v, %v, want false, true", c.Flagged(true), c.Flagged(false))
+	}
+}
+
+func TestNewClock_noClock(t *testing.T) {
+	for _, tag := range []string{"?", "-"} {
+		tc, _ := Parse(tag)
+		if _, err := NewClock(tc); err != ErrNoClock {
+			t.Errorf("NewClock(%v) error = %v, want %v", tag, err, ErrNoClock)
+		}
+	}
+}
+
+func parseGame(t *testing.T, s string) pgn.Game {
+	games, err := pgn.Parse(strings.NewReader(s))
+	if err != nil || len(games) != 1 {
+		t.Fatalf("Could not parse game: %v", err)
+	}
+	return games[0]
+}
+
+func TestReconstruct(t *testing.T) {
+	tests := []struct {
+		name string
+		game string
+		want []MoveTime
+	}{
+		{
+			"clocks",
+			`[TimeControl "60+1"]
+
+1. e4 { [%clk 0:01:00] } 1... e5 { [%clk 0:00:58] } 2. Nf3 { [%clk 0:00:55] } 1-0`,
+			[]MoveTime{{time.Second, time.Minute}, {3 * time.Second, 58 * time.Second}, {6 * time.Second, 55 * time.Second}},
+		},
+		{
+			"elapsed times only",
+			`[TimeControl "40/7200:3600"]
+
+1. e4 { [%emt 0:01:00] } 1... e5 { [%emt 0:00:30] } 1-0`,
+			[]MoveTime{{time.Minute, 119 * time.Minute}, {30 * time.Second, 7170 * time.Second}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := parseGame(t, tt.game)
+			tc, _ := Parse(g.Tags["TimeControl"])
+			got, err := Reconstruct(tc, g.Moves)
+			if err != nil {
+				t.Fatalf("Reconstruct() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Reconstruct() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	g := parseGame(t, `[TimeControl "60"]
+
+1. e4 { [%emt 0:00:01] } 1... e5 1-0`)
+	tc, _ := Parse("60")
+	if got, err := Reconstruct(tc, g.Moves); err == nil || len(got) != 1 {
+		t.Errorf("Reconstruct() = %v, %v, want one move and an error", got, err)
+	}
+}
+
+func TestForfeit(t *testing.T) {
+	tests := []struct {
+		name        string
+		game        string
+		wantForfeit bool
+		wantWhite   bool
+	}{
+		{"lichess termination", `[Result "1-0"]
+[Termination "Time forfeit"]
+
+1. e4 1-0`, true, false},
+		{"chess.com termination", `[Result "0-1"]
+[Termination "Bob won on time"]
+
+1. e4 0-1`, true, true},
+		{"clock reached zero", `[Result "0-1"]
+[TimeControl "60+0"]
+
+1. e4 { [%clk 0:00:10] } 1... e5 { [%clk 0:00:30] } 2. Nf3 { [%clk 0:00:00] } 0-1`, true, true},
+		{"resignation", `[Result "1-0"]
+[TimeControl "60+0"]
+[Termination "Normal"]
+
+1. e4 { [%clk 0:00:10] } 1-0`, false, false},
+		{"draw", `[Result "1/2-1/2"]
+[Termination "Time forfeit"]
+
+1. e4 1/2-1/2`, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forfeit, white := Forfeit(parseGame(t, tt.game))
+			if forfeit != tt.wantForfeit || white != tt.wantWhite {
+				t.Errorf("Forfeit() = %v, %v, want %v, %v", forfeit, white, tt.wantForfeit, tt.wantWhite)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package timeuse
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// Summary is a usage reduced to averages for CSV and JSON output. Times are in seconds and
+// evaluation drops in pawns.
+type Summary struct {
+	// Game identifies the game for a single game's usage
+	Game             string
+	Player           string
+	Games            int
+	Moves            int
+	AverageMoveTime  float64
+	Opening          float64
+	Middlegame       float64
+	Endgame          float64
+	TimeTroubleRate  float64
+	TimeTroubleMoves int
+	// Brackets are the average move times keyed by the share of the initial time left
+	Brackets                   map[string]float64
+	AverageEvalDrop            float64
+	AverageTimeTroubleEvalDrop float64
+	Correlation                float64
+}
+
+// Summary returns the averages of the usage
+func (u *Usage) Summary() Summary {
+	s := Summary{
+		Player:                     u.Player,
+		Games:                      u.Games,
+		Moves:                      u.Moves,
+		AverageMoveTime:            u.Average(),
+		Opening:                    u.Phases[Opening].Average(),
+		Middlegame:                 u.Phases[Middlegame].Average(),
+		Endgame:                    u.Phases[Endgame].Average(),
+		TimeTroubleRate:            u.TimeTroubleRate(),
+		TimeTroubleMoves:           u.TimeTrouble.Moves,
+		Brackets:                   make(map[string]float64),
+		AverageEvalDrop:            u.AverageEvalDrop(),
+		AverageTimeTroubleEvalDrop: u.AverageTimeTroubleEvalDrop(),
+		Correlation:                u.Correlation(),
+	}
+	for b, bucket := range u.Brackets {
+		s.Brackets[Bracket(b).String()] = bucket.Average()
+	}
+	return s
+}
+
+// WriteCSV writes summaries as CSV with a header record
+func WriteCSV(w io.Writer, summaries []Summary) error {
+	header := []string{"game", "player", "games", "moves", "average", "opening", "middlegame", "endgame", "time trouble rate", "time trouble moves"}
+	for _, name := range bracketNames {
+		header = append(header, "clock "+name)
+	}
+	header = append(header, "eval drop", "time trouble eval drop", "time eval correlation")
+
+	cw := csv.NewWriter(w)
+	cw.Write(header)
+	for _, s := range summaries {
+		record := []string{
+			s.Game,
+			s.Player,
+			strconv.Itoa(s.Games),
+			strconv.Itoa(s.Moves),
+			formatFloat(s.AverageMoveTime),
+			formatFloat(s.Opening),
+			formatFloat(s.Middlegame),
+			formatFloat(s.Endgame),
+			formatFloat(s.TimeTroubleRate),
+			strconv.Itoa(s.TimeTroubleMoves),
+		}
+		for _, name := range bracketNames {
+			record = append(record, formatFloat(s.Brackets[name]))
+		}
+		record = append(record, formatFloat(s.AverageEvalDrop), formatFloat(s.AverageTimeTroubleEvalDrop), formatFloat(s.Correlation))
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
@@ -0,0 +1,325 @@
+// Package timeuse analyses where players spend their time using the [%clk], [%emt] and
+// [%timestamp] commands in game comments.
+//
+// Time is split by game phase, by how much time was left on the clock and by whether the
+// player was in time trouble. When comments also have [%eval] commands, as in lichess
+// exports, the time spent on moves is compared with how much the evaluation dropped.
+//
+// Phases are decided by move number, set with Options.
+package timeuse
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/schafer14/go-chess/pgn"
+	"github.com/schafer14/go-chess/timecontrol"
+)
+
+// Phase is a part of a game
+type Phase int
+
+// Phases of a game by move number
+const (
+	Opening Phase = iota
+	Middlegame
+	Endgame
+)
+
+var phaseNames = []string{"opening", "middlegame", "endgame"}
+
+// String returns the lower case name of the phase
+func (p Phase) String() string {
+	return phaseNames[p]
+}
+
+// Bracket is a range of the time left on the clock before a move as a share of the initial time
+type Bracket int
+
+// Brackets of the time left before a move
+const (
+	Above75 Bracket = iota
+	Above50
+	Above25
+	Above10
+	Below10
+)
+
+var bracketNames = []string{">75%", "50-75%", "25-50%", "10-25%", "<10%"}
+
+// String returns the range of the bracket as percentages
+func (b Bracket) String() string {
+	return bracketNames[b]
+}
+
+func bracket(share float64) Bracket {
+	switch {
+	case share >= 0.75:
+		return Above75
+	case share >= 0.5:
+		return Above50
+	case share >= 0.25:
+		return Above25
+	case share >= 0.1:
+		return Above10
+	}
+	return Below10
+}
+
+// Options configure the analysis
+type Options struct {
+	// OpeningMoves is the last move of the opening and EndgameMoves the last move of the
+	// middlegame
+	OpeningMoves int
+	EndgameMoves int
+	// TimeTrouble is the share of the initial time below which a player is in time trouble
+	TimeTrouble float64
+}
+
+// DefaultOptions end the opening after move 15, start the endgame after move 40 and count time
+// trouble from under 10% of the initial time
+var DefaultOptions = Options{OpeningMoves: 15, EndgameMoves: 40, TimeTrouble: 0.1}
+
+// Bucket is the time spent on a group of moves
+type Bucket struct {
+	Moves   int
+	Seconds float64
+}
+
+// Average returns the average number of seconds per move
+func (b Bucket) Average() float64 {
+	if b.Moves == 0 {
+		return 0
+	}
+	return b.Seconds / float64(b.Moves)
+}
+
+func (b *Bucket) add(seconds float64) {
+	b.Moves++
+	b.Seconds += seconds
+}
+
+// Usage is a player's time usage over one or more games
+type Usage struct {
+	Player string
+	Games  int
+	Bucket
+	Phases   [3]Bucket
+	Brackets [5]Bucket
+	// TimeTroubleGames are the games in which the player was in time trouble for at least one
+	// move
+	TimeTroubleGames int
+	TimeTrouble      Bucket
+	// EvalMoves is the number of moves with an evaluation before and after them and EvalDrop
+	// the total number of pawns the player's evaluation dropped on them
+	EvalMoves            int
+	EvalDrop             float64
+	TimeTroubleEvalMoves int
+	TimeTroubleEvalDrop  float64
+
+	// sums for the correlation between the seconds spent on a move and the evaluation drop
+	n, sx, sy, sxx, syy, sxy float64
+}
+
+// TimeTroubleRate returns the share of games in which the player was in time trouble
+func (u *Usage) TimeTroubleRate() float64 {
+	if u.Games == 0 {
+		return 0
+	}
+	return float64(u.TimeTroubleGames) / float64(u.Games)
+}
+
+// AverageEvalDrop returns the average evaluation drop in pawns per move
+func (u *Usage) AverageEvalDrop() float64 {
+	if u.EvalMoves == 0 {
+		return 0
+	}
+	return u.EvalDrop / float64(u.EvalMoves)
+}
+
+// AverageTimeTroubleEvalDrop returns the average evaluation drop of moves made in time trouble
+func (u *Usage) AverageTimeTroubleEvalDrop() float64 {
+	if u.TimeTroubleEvalMoves == 0 {
+		return 0
+	}
+	return u.TimeTroubleEvalDrop / float64(u.TimeTroubleEvalMoves)
+}
+
+// Correlation returns the Pearson correlation between the time spent on a move and the
+// evaluation drop. A negative value means moves played quickly lost more. It is zero without
+// enough evaluated moves.
+func (u *Usage) Correlation() float64 {
+	if u.n < 2 {
+		return 0
+	}
+	cov := u.sxy - u.sx*u.sy/u.n
+	vx := u.sxx - u.sx*u.sx/u.n
+	vy := u.syy - u.sy*u.sy/u.n
+	if vx <= 0 || vy <= 0 {
+		return 0
+	}
+	return cov / math.Sqrt(vx*vy)
+}
+
+// Add adds the usage of other games to u
+func (u *Usage) Add(other Usage) {
+	u.Games += other.Games
+	u.Moves += other.Moves
+	u.Seconds += other.Seconds
+	for i := range u.Phases {
+		u.Phases[i].Moves += other.Phases[i].Moves
+		u.Phases[i].Seconds += other.Phases[i].Seconds
+	}
+	for i := range u.Brackets {
+		u.Brackets[i].Moves += other.Brackets[i].Moves
+		u.Brackets[i].Seconds += other.Brackets[i].Seconds
+	}
+	u.TimeTroubleGames += other.TimeTroubleGames
+	u.TimeTrouble.Moves += other.TimeTrouble.Moves
+	u.TimeTrouble.Seconds += other.TimeTrouble.Seconds
+	u.EvalMoves += other.EvalMoves
+	u.EvalDrop += other.EvalDrop
+	u.TimeTroubleEvalMoves += other.TimeTroubleEvalMoves
+	u.TimeTroubleEvalDrop += other.TimeTroubleEvalDrop
+	u.n += other.n
+	u.sx += other.sx
+	u.sy += other.sy
+	u.sxx += other.sxx
+	u.syy += other.syy
+	u.sxy += other.sxy
+}
+
+// evalCommand matches evaluations such as [%eval 0.17] or [%eval #-3]
+var evalCommand = regexp.MustCompile(`\[%eval\s+([^\]\s]+)`)
+
+// maxEval caps evaluations so mates and lost positions don't swamp the drops
+const maxEval = 10
+
+// Eval returns the evaluation in pawns from white's point of view in a move's comment. Mates
+// count as the largest evaluation.
+func Eval(m pgn.Move) (float64, bool) {
+	match := evalCommand.FindStringSubmatch(m.Annotation)
+	if match == nil {
+		return 0, false
+	}
+	if strings.HasPrefix(match[1], "#") {
+		if strings.HasPrefix(match[1], "#-") {
+			return -maxEval, true
+		}
+		return maxEval, true
+	}
+	eval, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	return math.Max(-maxEval, math.Min(maxEval, eval)), true
+}
+
+// Game returns the time usage of white and black in a game. Games without a known time control
+// or clock data return an error. When only some moves have clock data the moves up to the
+// first one without are used.
+func Game(g pgn.Game, opts Options) (white, black Usage, err error) {
+	tc, err := timecontrol.Parse(g.Tags["TimeControl"])
+	if err != nil {
+		return white, black, err
+	}
+	times, err := timecontrol.Reconstruct(tc, g.Moves)
+	if len(times) == 0 {
+		if err == nil {
+			err = timecontrol.ErrNoClock
+		}
+		return white, black, err
+	}
+
+	initial := tc.Stages[0].Time
+	usage := [2]*Usage{&white, &black}
+	white.Player, black.Player = g.Tags["White"], g.Tags["Black"]
+	white.Games, black.Games = 1, 1
+
+	remaining := [2]time.Duration{initial, initial}
+	var inTrouble [2]bool
+	prevEval, hasPrev := 0.0, false
+	for i, t := range times {
+		s := i % 2
+		u := usage[s]
+		seconds := t.Elapsed.Seconds()
+		share := float64(remaining[s]) / float64(initial)
+		trouble := share < opts.TimeTrouble
+		remaining[s] = t.Remaining
+
+		u.add(seconds)
+		u.Phases[phase(i/2+1, opts)].add(seconds)
+		u.Brackets[bracket(share)].add(seconds)
+		if trouble {
+			u.TimeTrouble.add(seconds)
+			inTrouble[s] = true
+		}
+
+		eval, ok := Eval(g.Moves[i])
+		if ok && hasPrev {
+			drop := prevEval - eval
+			if s == 1 {
+				drop = -drop
+			}
+			drop = math.Max(0, drop)
+
+			u.EvalMoves++
+			u.EvalDrop += drop
+			if trouble {
+				u.TimeTroubleEvalMoves++
+				u.TimeTroubleEvalDrop += drop
+			}
+			u.n++
+			u.sx += seconds
+			u.sy += drop
+			u.sxx += seconds * seconds
+			u.syy += drop * drop
+			u.sxy += seconds * drop
+		}
+		prevEval, hasPrev = eval, ok
+	}
+
+	for s, u := range usage {
+		if inTrouble[s] {
+			u.TimeTroubleGames = 1
+		}
+	}
+	return white, black, nil
+}
+
+func phase(move int, opts Options) Phase {
+	switch {
+	case move <= opts.OpeningMoves:
+		return Opening
+	case move <= opts.EndgameMoves:
+		return Middlegame
+	}
+	return Endgame
+}
+
+// Players returns the time usage of every player across games. Players are grouped by the
+// exact name in the White and Black tags. It also returns the number of games left out for
+// having no clock data.
+func Players(games []pgn.Game, opts Options) (map[string]*Usage, int) {
+	players := make(map[string]*Usage)
+	skipped := 0
+	for _, g := range games {
+		white, black, err := Game(g, opts)
+		if err != nil {
+			skipped++
+			continue
+		}
+		for _, u := range []Usage{white, black} {
+			p, ok := players[u.Player]
+			if !ok {
+				p = &Usage{Player: u.Player}
+				players[u.Player] = p
+			}
+			p.Add(u)
+		}
+	}
+	return players, skipped
+}
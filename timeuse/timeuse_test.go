@@ -0,0 +1,132 @@
+package timeuse
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/schafer14/go-chess/pgn"
+)
+
+const game = `[White "Alice"]
+[Black "Bob"]
+[Result "1-0"]
+[TimeControl "100+0"]
+
+1. e4 { [%eval 0.2] [%clk 0:01:35] } 1... e5 { [%eval 0.3] [%clk 0:01:30] } 2. Nf3 { [%eval 0.1] [%clk 0:00:10] }
+2... Nc6 { [%eval 2.5] [%clk 0:00:05] } 3. Bb5 { [%eval 2.5] [%clk 0:00:04] } 3... a6 { [%eval 9] [%clk 0:00:01] } 1-0
+`
+
+var opts = Options{OpeningMoves: 1, EndgameMoves: 2, TimeTrouble: 0.1}
+
+func parse(t *testing.T, s string) []pgn.Game {
+	games, err := pgn.Parse(strings.NewReader(s))
+	if err != nil {
+		t.Fatalf("Could not parse games: %v", err)
+	}
+	return games
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestGame(t *testing.T) {
+	white, black, err := Game(parse(t, game)[0], opts)
+	if err != nil {
+		t.Fatalf("Game() error = %v", err)
+	}
+
+	if white.Player != "Alice" || white.Moves != 3 || white.Seconds != 96 {
+		t.Errorf("Game() white = %v moves in %v seconds, want 3 in 96", white.Moves, white.Seconds)
+	}
+	if got := [3]float64{white.Phases[Opening].Seconds, white.Phases[Middlegame].Seconds, white.Phases[Endgame].Seconds}; got != [3]float64{5, 85, 6} {
+		t.Errorf("Game() white phases = %v, want [5 85 6]", got)
+	}
+	if white.TimeTroubleGames != 0 || white.Brackets[Above10].Moves != 1 {
+		t.Errorf("Game() white time trouble = %v, brackets %v", white.TimeTroubleGames, white.Brackets)
+	}
+	if white.EvalMoves != 2 || !near(white.EvalDrop, 0.2) {
+		t.Errorf("Game() white eval drop = %v over %v moves, want 0.2 over 2", white.EvalDrop, white.EvalMoves)
+	}
+
+	if black.TimeTroubleGames != 1 || black.TimeTrouble != (Bucket{1, 4}) {
+		t.Errorf("Game() black time trouble = %v, %v", black.TimeTroubleGames, black.TimeTrouble)
+	}
+	if black.Brackets[Above75] != (Bucket{2, 95}) || black.Brackets[Below10] != (Bucket{1, 4}) {
+		t.Errorf("Game() black brackets = %v", black.Brackets)
+	}
+	if !near(black.EvalDrop, 9) || !near(black.AverageTimeTroubleEvalDrop(), 6.5) {
+		t.Errorf("Game() black eval drop = %v, in time trouble %v", black.EvalDrop, black.AverageTimeTroubleEvalDrop())
+	}
+	if c := black.Correlation(); c >= 0 {
+		t.Errorf("Correlation() = %v, want quick moves to lose more", c)
+	}
+}
+
+func TestGame_noClock(t *testing.T) {
+	g := parse(t, `[TimeControl "60+0"]
+
+1. e4 e5 1-0`)[0]
+	if _, _, err := Game(g, opts); err == nil {
+		t.Errorf("Game() expected an error without clock data")
+	}
+}
+
+func TestPlayers(t *testing.T) {
+	games := parse(t, game+"\n"+game+"\n"+`[White "Alice"]
+[Black "Carol"]
+[Result "*"]
+[TimeControl "-"]
+
+1. e4 *
+`)
+	players, skipped := Players(games, opts)
+	if skipped != 1 || len(players) != 2 {
+		t.Fatalf("Players() = %v players and %v skipped, want 2 and 1", len(players), skipped)
+	}
+	bob := players["Bob"]
+	if bob.Games != 2 || bob.Moves != 6 || bob.TimeTroubleRate() != 1 || !near(bob.AverageEvalDrop(), 3) {
+		t.Errorf("Players() Bob = %+v", bob)
+	}
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		annotation string
+		want       float64
+		wantOk     bool
+	}{
+		{"[%eval 0.17]", 0.17, true},
+		{"Good [%eval -1.5] move", -1.5, true},
+		{"[%eval #-3]", -10, true},
+		{"[%eval #2]", 10, true},
+		{"[%eval 15]", 10, true},
+		{"No eval", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.annotation, func(t *testing.T) {
+			got, ok := Eval(pgn.Move{Annotation: tt.annotation})
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("Eval() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	players, _ := Players(parse(t, game), opts)
+	var b bytes.Buffer
+	if err := WriteCSV(&b, []Summary{players["Alice"].Summary()}); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[1], ",Alice,1,3,32.00,5.00,85.00,6.00,0.00,0,") {
+		t.Errorf("WriteCSV() = %q", b.String())
+	}
+	if h, r := strings.Count(lines[0], ","), strings.Count(lines[1], ","); h != r {
+		t.Errorf("WriteCSV() header has %v columns and record %v", h+1, r+1)
+	}
+}
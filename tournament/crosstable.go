@@ -0,0 +1,199 @@
+package tournament
+
+import (
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Style is the layout of a crosstable
+type Style int
+
+const (
+	// Auto uses Grid for round robins and Swiss otherwise
+	Auto Style = iota
+	// Grid has a column for every player with the results against them
+	Grid
+	// Swiss is a progressive table with a column for every round with the opponent, colour,
+	// result and the score after the round
+	Swiss
+)
+
+// Crosstable is a table of results ready to be written out. Rows are in standings order.
+type Crosstable struct {
+	Event   string
+	Headers []string
+	Rows    [][]string
+}
+
+// Crosstable builds the crosstable of the tournament.
+//
+// Grid cells are the results against the player numbered by the column, 1, ½ or 0, with the
+// results of every cycle concatenated. Swiss cells are the opponent's number, their colour and
+// the result followed by the player's score after the round, like 5w1 2.5. Byes are written as
+// +, = or - for the points they were worth and missed rounds only have the score.
+func (t *Tournament) Crosstable(style Style, tiebreaks []Tiebreak) *Crosstable {
+	if style == Auto {
+		style = Swiss
+		if t.IsRoundRobin() {
+			style = Grid
+		}
+	}
+
+	standings := t.Standings(tiebreaks)
+	number := make([]int, len(t.Players))
+	for n, s := range standings {
+		number[s.Player] = n + 1
+	}
+
+	ct := &Crosstable{Event: t.Event}
+	ct.Headers = []string{"No", "Rank", "Name", "Rating"}
+	switch style {
+	case Grid:
+		for n := range standings {
+			ct.Headers = append(ct.Headers, strconv.Itoa(n+1))
+		}
+	default:
+		for round := 1; round <= t.Rounds; round++ {
+			ct.Headers = append(ct.Headers, "R"+strconv.Itoa(round))
+		}
+	}
+	ct.Headers = append(ct.Headers, "Score")
+	for _, tb := range tiebreaks {
+		ct.Headers = append(ct.Headers, tb.String())
+	}
+
+	for n, s := range standings {
+		p := t.Players[s.Player]
+		row := []string{strconv.Itoa(n + 1), strconv.Itoa(s.Rank), p.Name, ""}
+		if p.Rating > 0 {
+			row[3] = strconv.Itoa(p.Rating)
+		}
+
+		switch style {
+		case Grid:
+			for _, opponent := range standings {
+				if opponent.Player == s.Player {
+					row = append(row, "X")
+					continue
+				}
+				var cell string
+				for _, g := range p.Games {
+					if g.Opponent == opponent.Player {
+						cell += result(g.Score)
+					}
+				}
+				row = append(row, cell)
+			}
+		default:
+			for round := 1; round <= t.Rounds; round++ {
+				var cell string
+				g, ok := p.GameInRound(round)
+				switch {
+				case !ok:
+				case g.Opponent == Bye:
+					cell = byeResult(g.Score) + " "
+				default:
+					colour := "w"
+					if g.Colour == Black {
+						colour = "b"
+					}
+					cell = strconv.Itoa(number[g.Opponent]) + colour + result(g.Score) + " "
+				}
+				row = append(row, cell+formatScore(p.ScoreBefore(round+1)))
+			}
+		}
+
+		row = append(row, formatScore(s.Score))
+		for _, tb := range s.Tiebreaks {
+			row = append(row, formatScore(tb))
+		}
+		ct.Rows = append(ct.Rows, row)
+	}
+
+	return ct
+}
+
+func result(score float64) string {
+	switch score {
+	case 1:
+		return "1"
+	case 0.5:
+		return "½"
+	default:
+		return "0"
+	}
+}
+
+func byeResult(score float64) string {
+	switch score {
+	case 1:
+		return "+"
+	case 0.5:
+		return "="
+	default:
+		return "-"
+	}
+}
+
+func formatScore(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// WriteText writes the crosstable as aligned plain text
+func (ct *Crosstable) WriteText(w io.Writer) error {
+	if ct.Event != "" {
+		if _, err := fmt.Fprintf(w, "%s\n\n", ct.Event); err != nil {
+			return err
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(ct.Headers, "\t"))
+	for _, row := range ct.Rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// WriteCSV writes the crosstable as CSV with a header record
+func (ct *Crosstable) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write(ct.Headers)
+	for _, row := range ct.Rows {
+		cw.Write(row)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteHTML writes the crosstable as an HTML table
+func (ct *Crosstable) WriteHTML(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("<table class=\"crosstable\">\n")
+	if ct.Event != "" {
+		fmt.Fprintf(&b, "<caption>%s</caption>\n", html.EscapeString(ct.Event))
+	}
+
+	b.WriteString("<thead><tr>")
+	for _, h := range ct.Headers {
+		fmt.Fprintf(&b, "<th>%s</th>", html.EscapeString(h))
+	}
+	b.WriteString("</tr></thead>\n<tbody>\n")
+
+	for _, row := range ct.Rows {
+		b.WriteString("<tr>")
+		for _, cell := range row {
+			fmt.Fprintf(&b, "<td>%s</td>", html.EscapeString(cell))
+		}
+		b.WriteString("</tr>\n")
+	}
+	b.WriteString("</tbody>\n</table>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,282 @@
+package tournament
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Tiebreak is a FIDE tiebreak system
+type Tiebreak int
+
+const (
+	// Buchholz is the sum of the opponents' scores
+	Buchholz Tiebreak = iota
+	// BuchholzCut1 and BuchholzCut2 leave out the lowest one or two opponent scores
+	BuchholzCut1
+	BuchholzCut2
+	// MedianBuchholz leaves out the highest and the lowest opponent scores
+	MedianBuchholz
+	// SonnebornBerger is the sum of the scores of the opponents beaten plus half the scores of
+	// the opponents drawn
+	SonnebornBerger
+	// DirectEncounter is the score in games between the tied players. In Standings they are the
+	// players equal on score and every earlier tiebreak, otherwise the players equal on score.
+	// It is only used when all of them have played each other, otherwise it is zero.
+	DirectEncounter
+	// Wins is the number of games won over the board
+	Wins
+	// AverageRatingOfOpponents is the average rating of the rated opponents played
+	AverageRatingOfOpponents
+)
+
+var tiebreakNames = []string{"BH", "BH-C1", "BH-C2", "BH-M", "SB", "DE", "WIN", "ARO"}
+
+// String returns the usual abbreviation of the tiebreak
+func (tb Tiebreak) String() string {
+	if int(tb) < len(tiebreakNames) {
+		return tiebreakNames[tb]
+	}
+	return fmt.Sprintf("Tiebreak(%d)", int(tb))
+}
+
+// ParseTiebreak parses a tiebreak abbreviation as returned by String
+func ParseTiebreak(s string) (Tiebreak, error) {
+	for i, name := range tiebreakNames {
+		if strings.EqualFold(s, name) {
+			return Tiebreak(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown tiebreak %q", s)
+}
+
+// DefaultSwissTiebreaks and DefaultRoundRobinTiebreaks are common tiebreak orders
+var (
+	DefaultSwissTiebreaks      = []Tiebreak{BuchholzCut1, Buchholz, SonnebornBerger, DirectEncounter, Wins}
+	DefaultRoundRobinTiebreaks = []Tiebreak{DirectEncounter, SonnebornBerger, Wins, AverageRatingOfOpponents}
+)
+
+// Standing is a player's place in the tournament
+type Standing struct {
+	// Rank is shared by players who are equal on score and every tiebreak
+	Rank int
+	// Player is the index of the player in Tournament.Players
+	Player    int
+	Score     float64
+	Tiebreaks []float64
+}
+
+// Standings ranks the players by score and then by the tiebreaks in order
+func (t *Tournament) Standings(tiebreaks []Tiebreak) []Standing {
+	standings := make([]Standing, len(t.Players))
+	for i, p := range t.Players {
+		standings[i] = Standing{Player: i, Score: p.Score(), Tiebreaks: make([]float64, len(tiebreaks))}
+	}
+	// Tiebreaks are computed in order as direct encounter depends on the ones before it
+	for k, tb := range tiebreaks {
+		for i := range standings {
+			if tb == DirectEncounter {
+				standings[i].Tiebreaks[k] = t.directEncounter(i, tiedWith(standings, i, k))
+			} else {
+				standings[i].Tiebreaks[k] = t.Tiebreak(tb, i)
+			}
+		}
+	}
+
+	// Players who are equal on everything keep their starting rank order
+	sort.SliceStable(standings, func(i, j int) bool {
+		return compare(standings[i], standings[j]) > 0
+	})
+
+	for i := range standings {
+		if i > 0 && compare(standings[i], standings[i-1]) == 0 {
+			standings[i].Rank = standings[i-1].Rank
+		} else {
+			standings[i].Rank = i + 1
+		}
+	}
+
+	return standings
+}
+
+// tiedWith returns the players equal to player i on score and the first n tiebreaks
+func tiedWith(standings []Standing, i, n int) map[int]bool {
+	tied := make(map[int]bool)
+	for j, s := range standings {
+		a, b := standings[i], s
+		a.Tiebreaks, b.Tiebreaks = a.Tiebreaks[:n], b.Tiebreaks[:n]
+		if compare(a, b) == 0 {
+			tied[j] = true
+		}
+	}
+	return tied
+}
+
+func compare(a, b Standing) int {
+	if a.Score != b.Score {
+		return sign(a.Score - b.Score)
+	}
+	for i := range a.Tiebreaks {
+		if d := a.Tiebreaks[i] - b.Tiebreaks[i]; math.Abs(d) > 1e-9 {
+			return sign(d)
+		}
+	}
+	return 0
+}
+
+func sign(f float64) int {
+	if f > 0 {
+		return 1
+	}
+	return -1
+}
+
+// Tiebreak computes a tiebreak for the player with index i.
+//
+// Unplayed games follow the FIDE rules: when computing an opponent's score for Buchholz and
+// Sonneborn-Berger, the opponent's byes and missed rounds count as draws, and a player's own bye
+// or missed round counts as a game against a virtual opponent who had the player's score before
+// the round and draws every later round.
+func (t *Tournament) Tiebreak(tb Tiebreak, i int) float64 {
+	switch tb {
+	case Buchholz:
+		return sum(t.opponentScores(i))
+	case BuchholzCut1:
+		return sum(cut(t.opponentScores(i), 1, 0))
+	case BuchholzCut2:
+		return sum(cut(t.opponentScores(i), 2, 0))
+	case MedianBuchholz:
+		return sum(cut(t.opponentScores(i), 1, 1))
+	case SonnebornBerger:
+		return t.sonnebornBerger(i)
+	case DirectEncounter:
+		score := t.Players[i].Score()
+		tied := make(map[int]bool)
+		for j, p := range t.Players {
+			if p.Score() == score {
+				tied[j] = true
+			}
+		}
+		return t.directEncounter(i, tied)
+	case Wins:
+		var wins float64
+		for _, g := range t.Players[i].Games {
+			if g.Opponent != Bye && g.Score == 1 {
+				wins++
+			}
+		}
+		return wins
+	case AverageRatingOfOpponents:
+		var total, n int
+		for _, g := range t.Players[i].Games {
+			if g.Opponent != Bye && t.Players[g.Opponent].Rating > 0 {
+				total += t.Players[g.Opponent].Rating
+				n++
+			}
+		}
+		if n == 0 {
+			return 0
+		}
+		return math.Floor(float64(total)/float64(n) + 0.5)
+	}
+	return 0
+}
+
+// adjustedScore is a player's score with their unplayed rounds counted as draws
+func (t *Tournament) adjustedScore(i int) float64 {
+	var score float64
+	var played int
+	for _, g := range t.Players[i].Games {
+		if g.Opponent != Bye {
+			score += g.Score
+			played++
+		}
+	}
+	return score + 0.5*float64(t.Rounds-played)
+}
+
+// virtualOpponent is the score of the virtual opponent for a round the player didn't play
+func (t *Tournament) virtualOpponent(i, round int, scored float64) float64 {
+	p := t.Players[i]
+	return p.ScoreBefore(round) + (1 - scored) + 0.5*float64(t.Rounds-round)
+}
+
+// roundScores calls f for each round with the opponent's tiebreak score and the points the
+// player scored that round
+func (t *Tournament) roundScores(i int, f func(opponent, scored float64)) {
+	p := t.Players[i]
+	for round := 1; round <= t.Rounds; round++ {
+		g, ok := p.GameInRound(round)
+		switch {
+		case !ok:
+			f(t.virtualOpponent(i, round, 0), 0)
+		case g.Opponent == Bye:
+			f(t.virtualOpponent(i, round, g.Score), g.Score)
+		default:
+			f(t.adjustedScore(g.Opponent), g.Score)
+		}
+	}
+}
+
+func (t *Tournament) opponentScores(i int) []float64 {
+	var scores []float64
+	t.roundScores(i, func(opponent, _ float64) {
+		scores = append(scores, opponent)
+	})
+	return scores
+}
+
+func (t *Tournament) sonnebornBerger(i int) float64 {
+	var sb float64
+	t.roundScores(i, func(opponent, scored float64) {
+		sb += opponent * scored
+	})
+	return sb
+}
+
+// directEncounter returns the player's score against the other tied players if every pair of
+// them has played
+func (t *Tournament) directEncounter(i int, tied map[int]bool) float64 {
+	if len(tied) < 2 {
+		return 0
+	}
+
+	for j := range tied {
+		met := make(map[int]bool)
+		for _, g := range t.Players[j].Games {
+			met[g.Opponent] = true
+		}
+		for k := range tied {
+			if j != k && !met[k] {
+				return 0
+			}
+		}
+	}
+
+	var de float64
+	for _, g := range t.Players[i].Games {
+		if g.Opponent != Bye && tied[g.Opponent] {
+			de += g.Score
+		}
+	}
+	return de
+}
+
+// cut removes the lowest low and highest high values
+func cut(values []float64, low, high int) []float64 {
+	if low+high >= len(values) {
+		return nil
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	return sorted[low : len(sorted)-high]
+}
+
+func sum(values []float64) float64 {
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
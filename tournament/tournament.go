@@ -0,0 +1,245 @@
+// Package tournament builds tournament standings and crosstables from the games of an event.
+//
+// A Tournament is built from the Event, Round, White, Black, WhiteElo, BlackElo and Result tags
+// of its games. Players are numbered by their starting rank: highest rated first, then by name.
+package tournament
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/schafer14/go-chess/pgn"
+)
+
+// Colour is the colour a player had in a game
+type Colour int
+
+const (
+	// NoColour is used for byes
+	NoColour Colour = iota
+	White
+	Black
+)
+
+// Bye is the opponent of a player who received a bye
+const Bye = -1
+
+// Game is a single round from one player's point of view
+type Game struct {
+	Round int
+	// Opponent is the index of the opponent in Tournament.Players or Bye
+	Opponent int
+	Colour   Colour
+	Score    float64
+}
+
+// Player is a tournament participant
+type Player struct {
+	Name   string
+	Rating int
+	// Games are the player's games in round order. Rounds the player missed have no entry.
+	Games []Game
+}
+
+// Score returns the total points scored including byes
+func (p *Player) Score() float64 {
+	var score float64
+	for _, g := range p.Games {
+		score += g.Score
+	}
+	return score
+}
+
+// ScoreBefore returns the points scored in rounds before round
+func (p *Player) ScoreBefore(round int) float64 {
+	var score float64
+	for _, g := range p.Games {
+		if g.Round < round {
+			score += g.Score
+		}
+	}
+	return score
+}
+
+// GameInRound returns the player's game in a round if they played or received a bye in it
+func (p *Player) GameInRound(round int) (Game, bool) {
+	for _, g := range p.Games {
+		if g.Round == round {
+			return g, true
+		}
+	}
+	return Game{}, false
+}
+
+// Tournament is a set of players and their results
+type Tournament struct {
+	Event   string
+	Players []*Player
+	// Rounds is the number of rounds played
+	Rounds int
+}
+
+// byeNames are the names used in place of an opponent to record a bye
+var byeNames = map[string]bool{"bye": true, "-": true, "": true}
+
+// IsBye reports whether a player name in a White or Black tag stands for a bye
+func IsBye(name string) bool {
+	return byeNames[strings.ToLower(strings.TrimSpace(name))]
+}
+
+// ByEvent splits games by their Event tag keeping the order events first appear in
+func ByEvent(games []pgn.Game) ([]string, map[string][]pgn.Game) {
+	var events []string
+	byEvent := make(map[string][]pgn.Game)
+	for _, g := range games {
+		event := g.Tags["Event"]
+		if _, ok := byEvent[event]; !ok {
+			events = append(events, event)
+		}
+		byEvent[event] = append(byEvent[event], g)
+	}
+	return events, byEvent
+}
+
+// FromGames builds a tournament from the games of a single event. Unfinished games are left
+// out. Games whose Round tag isn't a number, or is a round.board number like 3.1, use the
+// number before the dot; games without any round number are put in the round after each
+// player's last game.
+func FromGames(games []pgn.Game) *Tournament {
+	t := &Tournament{}
+	index := make(map[string]int)
+	ratings := make(map[string]int)
+
+	var names []string
+	for _, g := range games {
+		if t.Event == "" {
+			t.Event = g.Tags["Event"]
+		}
+		for _, side := range []string{"White", "Black"} {
+			name := g.Tags[side]
+			if IsBye(name) {
+				continue
+			}
+			if _, ok := index[name]; !ok {
+				index[name] = len(names)
+				names = append(names, name)
+			}
+			if r, err := strconv.Atoi(g.Tags[side+"Elo"]); err == nil && r > 0 {
+				ratings[name] = r
+			}
+		}
+	}
+
+	// Starting rank order
+	sort.SliceStable(names, func(i, j int) bool {
+		if ratings[names[i]] != ratings[names[j]] {
+			return ratings[names[i]] > ratings[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	for i, name := range names {
+		index[name] = i
+		t.Players = append(t.Players, &Player{Name: name, Rating: ratings[name]})
+	}
+
+	for _, g := range games {
+		var white float64
+		switch g.Tags["Result"] {
+		case "1-0":
+			white = 1
+		case "0-1":
+			white = 0
+		case "1/2-1/2":
+			white = 0.5
+		default:
+			continue
+		}
+
+		wName, bName := g.Tags["White"], g.Tags["Black"]
+		if IsBye(wName) && IsBye(bName) {
+			continue
+		}
+
+		round := roundNumber(g.Tags["Round"])
+		if round == 0 {
+			for _, name := range []string{wName, bName} {
+				if i, ok := index[name]; ok && len(t.Players[i].Games) > 0 {
+					last := t.Players[i].Games[len(t.Players[i].Games)-1].Round
+					if last >= round {
+						round = last + 1
+					}
+				}
+			}
+			if round == 0 {
+				round = 1
+			}
+		}
+		if round > t.Rounds {
+			t.Rounds = round
+		}
+
+		switch {
+		case IsBye(bName):
+			w := t.Players[index[wName]]
+			w.Games = append(w.Games, Game{Round: round, Opponent: Bye, Score: white})
+		case IsBye(wName):
+			b := t.Players[index[bName]]
+			b.Games = append(b.Games, Game{Round: round, Opponent: Bye, Score: 1 - white})
+		default:
+			wi, bi := index[wName], index[bName]
+			t.Players[wi].Games = append(t.Players[wi].Games, Game{Round: round, Opponent: bi, Colour: White, Score: white})
+			t.Players[bi].Games = append(t.Players[bi].Games, Game{Round: round, Opponent: wi, Colour: Black, Score: 1 - white})
+		}
+	}
+
+	for _, p := range t.Players {
+		sort.SliceStable(p.Games, func(i, j int) bool {
+			return p.Games[i].Round < p.Games[j].Round
+		})
+	}
+
+	return t
+}
+
+func roundNumber(tag string) int {
+	if i := strings.IndexByte(tag, '.'); i >= 0 {
+		tag = tag[:i]
+	}
+	n, err := strconv.Atoi(tag)
+	if err != nil || n < 1 {
+		return 0
+	}
+	return n
+}
+
+// IsRoundRobin reports whether every player met every other player the same number of times
+// and nobody received a bye
+func (t *Tournament) IsRoundRobin() bool {
+	if len(t.Players) < 2 {
+		return false
+	}
+
+	cycles := -1
+	for i, p := range t.Players {
+		met := make(map[int]int)
+		for _, g := range p.Games {
+			if g.Opponent == Bye {
+				return false
+			}
+			met[g.Opponent]++
+		}
+		for j := range t.Players {
+			if i == j {
+				continue
+			}
+			if cycles < 0 {
+				cycles = met[j]
+			}
+			if met[j] != cycles || cycles == 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,203 @@
+package tournament
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/schafer14/go-chess/internal/pgntest"
+	"github.com/schafer14/go-chess/pgn"
+)
+
+// roundRobin is a four player round robin won by A
+func roundRobin() *Tournament {
+	return FromGames(pgntest.Rated([]pgn.Game{
+		pgntest.Game("1.1", "", "A", "D", "1-0"),
+		pgntest.Game("1.2", "", "B", "C", "1/2-1/2"),
+		pgntest.Game("2.1", "", "D", "C", "0-1"),
+		pgntest.Game("2.2", "", "A", "B", "1/2-1/2"),
+		pgntest.Game("3.1", "", "B", "D", "1-0"),
+		pgntest.Game("3.2", "", "C", "A", "0-1"),
+		pgntest.Game("4", "", "A", "B", "*"),
+	}, map[string]string{"A": "2000", "B": "1900", "C": "1800", "D": "1700"}))
+}
+
+// swiss is a two round swiss with a bye in each round
+func swiss() *Tournament {
+	return FromGames(pgntest.Rated([]pgn.Game{
+		pgntest.Game("1", "", "P1", "P2", "1-0"),
+		pgntest.Game("1", "", "P3", "P4", "1-0"),
+		pgntest.Game("1", "", "P5", "BYE", "1-0"),
+		pgntest.Game("2", "", "P3", "P1", "1/2-1/2"),
+		pgntest.Game("2", "", "P5", "P2", "0-1"),
+		pgntest.Game("2", "", "BYE", "P4", "0-1"),
+	}, map[string]string{"P1": "2000", "P2": "1900", "P3": "1800", "P4": "1700", "P5": "1600"}))
+}
+
+func TestFromGames(t *testing.T) {
+	tr := roundRobin()
+	if tr.Event != "Test" || tr.Rounds != 3 || len(tr.Players) != 4 {
+		t.Fatalf("FromGames() = %v rounds %v players, want 3 rounds 4 players", tr.Rounds, len(tr.Players))
+	}
+	if !tr.IsRoundRobin() {
+		t.Errorf("IsRoundRobin() = false, want true")
+	}
+	if swiss().IsRoundRobin() {
+		t.Errorf("IsRoundRobin() = true for a swiss, want false")
+	}
+
+	want := []Game{{1, 3, White, 1}, {2, 1, White, 0.5}, {3, 2, Black, 1}}
+	if got := tr.Players[0].Games; !reflect.DeepEqual(got, want) {
+		t.Errorf("Games = %v, want %v", got, want)
+	}
+}
+
+func TestFromGames_unknownRounds(t *testing.T) {
+	tr := FromGames([]pgn.Game{
+		pgntest.Game("?", "", "A", "B", "1-0"),
+		pgntest.Game("?", "", "B", "A", "1-0"),
+		pgntest.Game("", "", "A", "C", "1-0"),
+	})
+	if tr.Rounds != 3 {
+		t.Errorf("Rounds = %v, want 3", tr.Rounds)
+	}
+}
+
+func TestTournament_Tiebreak(t *testing.T) {
+	rr, sw := roundRobin(), swiss()
+	tests := []struct {
+		name   string
+		t      *Tournament
+		tb     Tiebreak
+		player int
+		want   float64
+	}{
+		{"sonneborn berger", rr, SonnebornBerger, 0, 2.5},
+		{"wins", rr, Wins, 0, 2},
+		{"average rating of opponents", rr, AverageRatingOfOpponents, 0, 1800},
+		{"no tie for direct encounter", rr, DirectEncounter, 0, 0},
+		{"buchholz", sw, Buchholz, 0, 2.5},
+		{"buchholz cut 1", sw, BuchholzCut1, 0, 1.5},
+		{"median buchholz", sw, MedianBuchholz, 0, 0},
+		{"buchholz with own bye in first round", sw, Buchholz, 4, 1.5},
+		{"buchholz with own bye in last round", sw, Buchholz, 3, 1.5},
+		{"buchholz against a player with a bye", sw, Buchholz, 1, 2},
+		{"sonneborn berger with a draw", sw, SonnebornBerger, 0, 1.75},
+		{"direct encounter", sw, DirectEncounter, 2, 0.5},
+		{"direct encounter when not all tied players met", sw, DirectEncounter, 1, 0},
+		{"wins don't include byes", sw, Wins, 4, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.t.Tiebreak(tt.tb, tt.player); got != tt.want {
+				t.Errorf("Tiebreak(%v, %v) = %v, want %v", tt.tb, tt.player, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTournament_Standings(t *testing.T) {
+	standings := swiss().Standings([]Tiebreak{Buchholz})
+
+	var players, ranks []int
+	for _, s := range standings {
+		players = append(players, s.Player)
+		ranks = append(ranks, s.Rank)
+	}
+	if want := []int{0, 2, 1, 3, 4}; !reflect.DeepEqual(players, want) {
+		t.Errorf("Standings() players = %v, want %v", players, want)
+	}
+	if want := []int{1, 2, 3, 4, 4}; !reflect.DeepEqual(ranks, want) {
+		t.Errorf("Standings() ranks = %v, want %v", ranks, want)
+	}
+}
+
+func TestTournament_Standings_directEncounter(t *testing.T) {
+	// W, X, Y and Z are tied on one point but have not all met. Average rating of opponents
+	// splits them into X and Y, who met, and W and Z, who met.
+	tr := FromGames(pgntest.Rated([]pgn.Game{
+		pgntest.Game("1", "", "X", "Y", "1-0"),
+		pgntest.Game("1", "", "Z", "W", "1-0"),
+		pgntest.Game("1", "", "V", "U", "1/2-1/2"),
+		pgntest.Game("2", "", "Y", "V", "1-0"),
+		pgntest.Game("2", "", "X", "W", "0-1"),
+		pgntest.Game("2", "", "Z", "U", "0-1"),
+	}, map[string]string{"V": "2000", "W": "2000", "X": "2000", "Y": "2000", "Z": "1000", "U": "1000"}))
+
+	var got []string
+	for _, s := range tr.Standings([]Tiebreak{AverageRatingOfOpponents, DirectEncounter}) {
+		got = append(got, fmt.Sprintf("%v %v", tr.Players[s.Player].Name, s.Tiebreaks[1]))
+	}
+	want := []string{"U 0", "X 1", "Y 0", "Z 1", "W 0", "V 0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Standings() = %v, want %v", got, want)
+	}
+}
+
+func TestParseTiebreak(t *testing.T) {
+	for tb := Buchholz; tb <= AverageRatingOfOpponents; tb++ {
+		got, err := ParseTiebreak(strings.ToLower(tb.String()))
+		if err != nil || got != tb {
+			t.Errorf("ParseTiebreak(%v) = %v, %v", tb, got, err)
+		}
+	}
+	if _, err := ParseTiebreak("XYZ"); err == nil {
+		t.Errorf("ParseTiebreak(XYZ) expected an error")
+	}
+}
+
+func TestTournament_Crosstable(t *testing.T) {
+	tests := []struct {
+		name  string
+		t     *Tournament
+		style Style
+		want  []string
+	}{
+		{"grid", roundRobin(), Auto, []string{"1", "1", "A", "2000", "X", "½", "1", "1", "2.5", "2.5"}},
+		{"swiss", swiss(), Auto, []string{"1", "1", "P1", "2000", "3w1 1", "2b½ 1.5", "1.5", "1.75"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ct := tt.t.Crosstable(tt.style, []Tiebreak{SonnebornBerger})
+			if got := ct.Rows[0]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Crosstable() first row = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	ct := swiss().Crosstable(Swiss, nil)
+	if got := ct.Rows[4][4:6]; !reflect.DeepEqual(got, []string{"+ 1", "3w0 1"}) {
+		t.Errorf("Crosstable() bye row = %q", got)
+	}
+
+	// C missed the first round
+	late := FromGames([]pgn.Game{
+		pgntest.Game("1", "", "A", "B", "1-0"),
+		pgntest.Game("2", "", "A", "C", "1/2-1/2"),
+	})
+	ct = late.Crosstable(Swiss, nil)
+	if got := ct.Rows[1][2:6]; !reflect.DeepEqual(got, []string{"C", "", "0", "1b½ 0.5"}) {
+		t.Errorf("Crosstable() missed round row = %q", got)
+	}
+}
+
+func TestCrosstable_Write(t *testing.T) {
+	ct := &Crosstable{Event: "A & B", Headers: []string{"No", "Name"}, Rows: [][]string{{"1", "<Alice>"}}}
+
+	var b bytes.Buffer
+	if err := ct.WriteCSV(&b); err != nil || b.String() != "No,Name\n1,<Alice>\n" {
+		t.Errorf("WriteCSV() = %q, %v", b.String(), err)
+	}
+
+	b.Reset()
+	if err := ct.WriteHTML(&b); err != nil || !strings.Contains(b.String(), "<td>&lt;Alice&gt;</td>") || !strings.Contains(b.String(), "A &amp; B") {
+		t.Errorf("WriteHTML() = %q, %v", b.String(), err)
+	}
+
+	b.Reset()
+	if err := ct.WriteText(&b); err != nil || b.String() != "A & B\n\nNo Name\n1  <Alice>\n" {
+		t.Errorf("WriteText() = %q, %v", b.String(), err)
+	}
+}
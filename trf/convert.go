@@ -0,0 +1,200 @@
+package trf
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/schafer14/go-chess/pgn"
+	"github.com/schafer14/go-chess/tournament"
+)
+
+// Games converts the report to pgn stubs, one per game and bye, in round order. Games are
+// taken from the white player's record, or the lower numbered player's when neither had a
+// colour as in some forfeits. Byes are games against BYE and rounds a player wasn't paired in
+// are left out.
+func (f *File) Games() []pgn.Game {
+	players := make(map[int]Player)
+	rounds := 0
+	for _, p := range f.Players {
+		players[p.Number] = p
+		if len(p.Rounds) > rounds {
+			rounds = len(p.Rounds)
+		}
+	}
+
+	var games []pgn.Game
+	for round := 1; round <= rounds; round++ {
+		for _, p := range f.Players {
+			if round > len(p.Rounds) {
+				continue
+			}
+			r := p.Rounds[round-1]
+
+			var white, black, result string
+			var whiteElo, blackElo int
+			switch {
+			case r.Result == NotPaired:
+				continue
+			case r.IsBye():
+				white, whiteElo, black = p.Name, p.Rating, "BYE"
+				result = whiteResult(r.Score())
+			default:
+				opponent, ok := players[r.Opponent]
+				if !ok {
+					continue
+				}
+				if r.Colour == tournament.Black || (r.Colour == tournament.NoColour && p.Number > r.Opponent) {
+					continue
+				}
+				white, whiteElo, black, blackElo = p.Name, p.Rating, opponent.Name, opponent.Rating
+				result = whiteResult(r.Score())
+			}
+
+			tags := map[string]string{
+				"Event":  f.Name,
+				"Site":   f.City,
+				"Date":   f.roundDate(round),
+				"Round":  strconv.Itoa(round),
+				"White":  white,
+				"Black":  black,
+				"Result": result,
+			}
+			if whiteElo > 0 {
+				tags["WhiteElo"] = strconv.Itoa(whiteElo)
+			}
+			if blackElo > 0 {
+				tags["BlackElo"] = strconv.Itoa(blackElo)
+			}
+			if tags["Site"] == "" {
+				tags["Site"] = "?"
+			}
+			games = append(games, pgn.Game{Tags: tags})
+		}
+	}
+	return games
+}
+
+func whiteResult(score float64) string {
+	switch score {
+	case 1:
+		return "1-0"
+	case 0.5:
+		return "1/2-1/2"
+	}
+	return "0-1"
+}
+
+// roundDate returns the pgn date of a round from the round dates or the start date
+func (f *File) roundDate(round int) string {
+	if round <= len(f.RoundDates) {
+		if parts := strings.Split(f.RoundDates[round-1], "/"); len(parts) == 3 && len(parts[0]) == 2 {
+			if year, err := strconv.Atoi(parts[0]); err == nil {
+				return strconv.Itoa(f.fullYear(year)) + "." + parts[1] + "." + parts[2]
+			}
+		}
+	}
+	if d := strings.Replace(f.StartDate, "/", ".", -1); len(d) == 10 {
+		return d
+	}
+	return "????.??.??"
+}
+
+// fullYear returns the year of a round date with a two digit year. The century is the one that
+// puts the year nearest the start date, or without one the year is taken as 1969 to 2068.
+func (f *File) fullYear(year int) int {
+	start, err := strconv.Atoi(strings.SplitN(f.StartDate, "/", 2)[0])
+	if err != nil || start < 100 {
+		if year >= 69 {
+			return 1900 + year
+		}
+		return 2000 + year
+	}
+
+	full := start - start%100 + year
+	switch {
+	case full < start-50:
+		full += 100
+	case full > start+50:
+		full -= 100
+	}
+	return full
+}
+
+// FromGames builds a report from the games of a single event. Players are numbered by their
+// starting rank and ranked by score with the default Swiss tiebreaks. Titles and FIDE ids are
+// taken from the WhiteTitle, BlackTitle, WhiteFideId and BlackFideId tags.
+func FromGames(games []pgn.Game) *File {
+	t := tournament.FromGames(games)
+	f := &File{Name: t.Event}
+
+	titles := make(map[string]string)
+	ids := make(map[string]string)
+	var dates []string
+	for _, g := range games {
+		for _, side := range []string{"White", "Black"} {
+			if title := g.Tags[side+"Title"]; title != "" && title != "-" {
+				titles[g.Tags[side]] = title
+			}
+			if id := g.Tags[side+"FideId"]; id != "" {
+				ids[g.Tags[side]] = id
+			}
+		}
+		if f.City == "" && g.Tags["Site"] != "?" {
+			f.City = g.Tags["Site"]
+		}
+		if d := g.Tags["Date"]; len(d) == 10 && !strings.Contains(d, "?") {
+			dates = append(dates, d)
+		}
+	}
+	if len(dates) > 0 {
+		sort.Strings(dates)
+		f.StartDate = strings.Replace(dates[0], ".", "/", -1)
+		f.EndDate = strings.Replace(dates[len(dates)-1], ".", "/", -1)
+	}
+
+	ranks := make([]int, len(t.Players))
+	for _, s := range t.Standings(tournament.DefaultSwissTiebreaks) {
+		ranks[s.Player] = s.Rank
+	}
+
+	for i, tp := range t.Players {
+		p := Player{
+			Number: i + 1,
+			Title:  titles[tp.Name],
+			Name:   tp.Name,
+			Rating: tp.Rating,
+			ID:     ids[tp.Name],
+			Points: tp.Score(),
+			Rank:   ranks[i],
+		}
+		for round := 1; round <= t.Rounds; round++ {
+			g, ok := tp.GameInRound(round)
+			switch {
+			case !ok:
+				p.Rounds = append(p.Rounds, Round{Result: ZeroPointBye})
+			case g.Opponent == tournament.Bye:
+				result := byte(ZeroPointBye)
+				switch g.Score {
+				case 1:
+					result = PairingBye
+				case 0.5:
+					result = HalfPointBye
+				}
+				p.Rounds = append(p.Rounds, Round{Result: result})
+			default:
+				result := byte(Loss)
+				switch g.Score {
+				case 1:
+					result = Win
+				case 0.5:
+					result = Draw
+				}
+				p.Rounds = append(p.Rounds, Round{Opponent: g.Opponent + 1, Colour: g.Colour, Result: result})
+			}
+		}
+		f.Players = append(f.Players, p)
+	}
+
+	return f
+}
@@ -0,0 +1,310 @@
+// Package trf reads and writes FIDE Tournament Report Files (TRF-16).
+//
+// A report has header records such as the tournament name (012) and dates (042, 052), one 001
+// record per player with their round by round results and, optionally, the round dates (132).
+// Records this package doesn't interpret are kept as they are so files can be read and written
+// back without losing them.
+package trf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/schafer14/go-chess/tournament"
+)
+
+// Result codes of a round
+const (
+	Win          = '1'
+	Draw         = '='
+	Loss         = '0'
+	ForfeitWin   = '+'
+	ForfeitLoss  = '-'
+	UnratedWin   = 'W'
+	UnratedDraw  = 'D'
+	UnratedLoss  = 'L'
+	HalfPointBye = 'H'
+	FullPointBye = 'F'
+	PairingBye   = 'U'
+	ZeroPointBye = 'Z'
+	NotPaired    = ' '
+)
+
+// Record codes and the layout of player records
+const (
+	playerRecord   = "001"
+	roundDates     = "132"
+	firstRoundCol  = 92
+	roundWidth     = 10
+	nameWidth      = 33
+	minPlayerWidth = 89
+)
+
+// File is a tournament report
+type File struct {
+	Name         string
+	City         string
+	Federation   string
+	StartDate    string
+	EndDate      string
+	Type         string
+	ChiefArbiter string
+	TimeControl  string
+	// RoundDates are the dates of the rounds as YY/MM/DD
+	RoundDates []string
+	Players    []Player
+	// Other are the records that aren't interpreted, in the order they were read
+	Other []string
+}
+
+// Player is a 001 player record
+type Player struct {
+	// Number is the starting rank
+	Number     int
+	Sex        string
+	Title      string
+	Name       string
+	Rating     int
+	Federation string
+	ID         string
+	BirthDate  string
+	Points     float64
+	Rank       int
+	Rounds     []Round
+}
+
+// Round is a player's result in one round
+type Round struct {
+	// Opponent is the opponent's starting rank or 0 for byes and unpaired rounds
+	Opponent int
+	Colour   tournament.Colour
+	Result   byte
+}
+
+// Score returns the points the result is worth
+func (r Round) Score() float64 {
+	switch r.Result {
+	case Win, ForfeitWin, UnratedWin, FullPointBye, PairingBye:
+		return 1
+	case Draw, UnratedDraw, HalfPointBye:
+		return 0.5
+	}
+	return 0
+}
+
+// IsBye reports whether the round is a bye or a round the player wasn't paired in
+func (r Round) IsBye() bool {
+	return r.Opponent == 0
+}
+
+// headers are the header records with a single value
+var headers = []struct {
+	code  string
+	field func(f *File) *string
+}{
+	{"012", func(f *File) *string { return &f.Name }},
+	{"022", func(f *File) *string { return &f.City }},
+	{"032", func(f *File) *string { return &f.Federation }},
+	{"042", func(f *File) *string { return &f.StartDate }},
+	{"052", func(f *File) *string { return &f.EndDate }},
+	{"092", func(f *File) *string { return &f.Type }},
+	{"102", func(f *File) *string { return &f.ChiefArbiter }},
+	{"122", func(f *File) *string { return &f.TimeControl }},
+}
+
+// Read reads a tournament report. The number of players (062) and rated players (072) are
+// left out as they are computed when writing.
+func Read(r io.Reader) (*File, error) {
+	f := &File{}
+	s := bufio.NewScanner(r)
+	line := 0
+
+records:
+	for s.Scan() {
+		line++
+		text := strings.TrimRight(s.Text(), " \r")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		runes := []rune(text)
+		code := column(runes, 1, 3)
+
+		switch code {
+		case playerRecord:
+			p, err := readPlayer(runes)
+			if err != nil {
+				return nil, fmt.Errorf("line %v: %v", line, err)
+			}
+			f.Players = append(f.Players, p)
+			continue
+		case roundDates:
+			for col := firstRoundCol; col <= len(runes); col += roundWidth {
+				f.RoundDates = append(f.RoundDates, column(runes, col, col+7))
+			}
+			continue
+		case "062", "072":
+			continue
+		}
+
+		for _, h := range headers {
+			if h.code == code {
+				*h.field(f) = column(runes, 5, len(runes))
+				continue records
+			}
+		}
+		f.Other = append(f.Other, text)
+	}
+
+	return f, s.Err()
+}
+
+// column returns the text between two 1-based columns inclusive with spaces trimmed. Columns
+// count characters rather than bytes so names with accents don't shift the fields after them.
+func column(line []rune, from, to int) string {
+	if from > len(line) {
+		return ""
+	}
+	if to > len(line) {
+		to = len(line)
+	}
+	return strings.TrimSpace(string(line[from-1 : to]))
+}
+
+func readPlayer(line []rune) (Player, error) {
+	if len(line) < minPlayerWidth {
+		return Player{}, fmt.Errorf("player record is %v characters, want at least %v", len(line), minPlayerWidth)
+	}
+
+	var p Player
+	var err error
+	if p.Number, err = strconv.Atoi(column(line, 5, 8)); err != nil {
+		return p, fmt.Errorf("invalid starting rank %q", column(line, 5, 8))
+	}
+	p.Sex = column(line, 10, 10)
+	p.Title = column(line, 11, 13)
+	p.Name = column(line, 15, 47)
+	if rating := column(line, 49, 52); rating != "" {
+		if p.Rating, err = strconv.Atoi(rating); err != nil {
+			return p, fmt.Errorf("invalid rating %q", rating)
+		}
+	}
+	p.Federation = column(line, 54, 56)
+	p.ID = column(line, 58, 68)
+	p.BirthDate = column(line, 70, 79)
+	if points := column(line, 81, 84); points != "" {
+		if p.Points, err = strconv.ParseFloat(points, 64); err != nil {
+			return p, fmt.Errorf("invalid points %q", points)
+		}
+	}
+	if rank := column(line, 86, 89); rank != "" {
+		if p.Rank, err = strconv.Atoi(rank); err != nil {
+			return p, fmt.Errorf("invalid rank %q", rank)
+		}
+	}
+
+	for col := firstRoundCol; col <= len(line); col += roundWidth {
+		var r Round
+		if opponent := column(line, col, col+3); opponent != "" {
+			if r.Opponent, err = strconv.Atoi(opponent); err != nil {
+				return p, fmt.Errorf("round %v: invalid opponent %q", len(p.Rounds)+1, opponent)
+			}
+		}
+		switch column(line, col+5, col+5) {
+		case "w":
+			r.Colour = tournament.White
+		case "b":
+			r.Colour = tournament.Black
+		}
+		r.Result = NotPaired
+		if result := column(line, col+7, col+7); result != "" {
+			r.Result = result[0]
+		}
+		p.Rounds = append(p.Rounds, r)
+	}
+
+	return p, nil
+}
+
+// Write writes the tournament report
+func (f *File) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	for _, h := range headers[:5] {
+		writeHeader(bw, h.code, *h.field(f))
+	}
+	rated := 0
+	for _, p := range f.Players {
+		if p.Rating > 0 {
+			rated++
+		}
+	}
+	writeHeader(bw, "062", strconv.Itoa(len(f.Players)))
+	writeHeader(bw, "072", strconv.Itoa(rated))
+	for _, h := range headers[5:] {
+		writeHeader(bw, h.code, *h.field(f))
+	}
+
+	if len(f.RoundDates) > 0 {
+		bw.WriteString(roundDates + strings.Repeat(" ", firstRoundCol-len(roundDates)-1))
+		for i, d := range f.RoundDates {
+			if i > 0 {
+				bw.WriteString("  ")
+			}
+			fmt.Fprintf(bw, "%-8s", d)
+		}
+		bw.WriteString("\n")
+	}
+
+	for _, p := range f.Players {
+		writePlayer(bw, p)
+	}
+	for _, o := range f.Other {
+		bw.WriteString(o + "\n")
+	}
+
+	return bw.Flush()
+}
+
+func writeHeader(w *bufio.Writer, code, value string) {
+	if value != "" {
+		w.WriteString(code + " " + value + "\n")
+	}
+}
+
+func writePlayer(w *bufio.Writer, p Player) {
+	rating := ""
+	if p.Rating > 0 {
+		rating = strconv.Itoa(p.Rating)
+	}
+	name := p.Name
+	if runes := []rune(name); len(runes) > nameWidth {
+		name = string(runes[:nameWidth])
+	}
+
+	fmt.Fprintf(w, "%s %4d %1.1s%3.3s %-33s %4s %3.3s %11.11s %10.10s %4.1f %4d",
+		playerRecord, p.Number, p.Sex, p.Title, name, rating, p.Federation, p.ID, p.BirthDate, p.Points, p.Rank)
+
+	for _, r := range p.Rounds {
+		opponent := "    "
+		if r.Opponent > 0 {
+			opponent = fmt.Sprintf("%4d", r.Opponent)
+		} else if r.Result != NotPaired {
+			opponent = "0000"
+		}
+		colour := byte('-')
+		switch r.Colour {
+		case tournament.White:
+			colour = 'w'
+		case tournament.Black:
+			colour = 'b'
+		}
+		if r.Opponent == 0 && r.Result == NotPaired {
+			colour = ' '
+		}
+		fmt.Fprintf(w, "  %s %c %c", opponent, colour, r.Result)
+	}
+	w.WriteString("\n")
+}
@@ -0,0 +1,203 @@
+package trf
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/schafer14/go-chess/pgn"
+	"github.com/schafer14/go-chess/tournament"
+)
+
+const report = `012 Winter Open
+022 Hobart
+042 2019/06/01
+052 2019/06/02
+062 3
+072 2
+092 Individual: Swiss-System
+102 Arbiter, Alice
+132                                                                                        19/06/01  19/06/02
+001    1 f GM Alpha, Anna                       2100 AUS    12345678 1990/01/01  1.5    1     2 w 1     3 b =
+001    2 m    Beta, Ben                         1900 AUS                         0.5    3     1 b 0  0000 - H
+001    3 m FM Gamma, Gil                             NZL    87654321             1.5    2  0000 - U     1 w =
+XXR 2
+`
+
+func TestRead(t *testing.T) {
+	f, err := Read(strings.NewReader(report))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	if f.Name != "Winter Open" || f.City != "Hobart" || f.StartDate != "2019/06/01" || f.ChiefArbiter != "Arbiter, Alice" {
+		t.Errorf("Read() headers = %+v", f)
+	}
+	if want := []string{"19/06/01", "19/06/02"}; !reflect.DeepEqual(f.RoundDates, want) {
+		t.Errorf("RoundDates = %v, want %v", f.RoundDates, want)
+	}
+	if want := []string{"XXR 2"}; !reflect.DeepEqual(f.Other, want) {
+		t.Errorf("Other = %v, want %v", f.Other, want)
+	}
+
+	want := Player{
+		Number:     1,
+		Sex:        "f",
+		Title:      "GM",
+		Name:       "Alpha, Anna",
+		Rating:     2100,
+		Federation: "AUS",
+		ID:         "12345678",
+		BirthDate:  "1990/01/01",
+		Points:     1.5,
+		Rank:       1,
+		Rounds:     []Round{{2, tournament.White, Win}, {3, tournament.Black, Draw}},
+	}
+	if !reflect.DeepEqual(f.Players[0], want) {
+		t.Errorf("Players[0] = %+v, want %+v", f.Players[0], want)
+	}
+	if got := f.Players[1].Rounds[1]; !got.IsBye() || got.Score() != 0.5 {
+		t.Errorf("half point bye = %+v", got)
+	}
+}
+
+func TestRead_invalid(t *testing.T) {
+	if _, err := Read(strings.NewReader("001    1 m    Short\n")); err == nil {
+		t.Errorf("Read() expected an error for a short player record")
+	}
+}
+
+func TestFile_Write(t *testing.T) {
+	f, err := Read(strings.NewReader(report))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	var b bytes.Buffer
+	if err := f.Write(&b); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if b.String() != report {
+		t.Errorf("Write() = \n%v\nwant\n%v", b.String(), report)
+	}
+}
+
+func TestFile_Write_accents(t *testing.T) {
+	f := &File{Players: []Player{{
+		Number:     1,
+		Name:       "Gölz, Jürgen",
+		Rating:     2345,
+		Federation: "GER",
+		Points:     1,
+		Rank:       1,
+		Rounds:     []Round{{2, tournament.White, Win}},
+	}, {
+		Number: 2,
+		Name:   strings.Repeat("Ø", 40),
+		Rounds: []Round{{1, tournament.Black, Loss}},
+	}}}
+
+	var b bytes.Buffer
+	if err := f.Write(&b); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	back, err := Read(&b)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	f.Players[1].Name = strings.Repeat("Ø", nameWidth)
+	if !reflect.DeepEqual(back.Players, f.Players) {
+		t.Errorf("Read() = %+v, want %+v", back.Players, f.Players)
+	}
+}
+
+func TestFile_Games(t *testing.T) {
+	f, err := Read(strings.NewReader(report))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+
+	var got []string
+	for _, g := range f.Games() {
+		got = append(got, g.Tags["Round"]+" "+g.Tags["Date"]+" "+g.Tags["White"]+" "+g.Tags["Result"]+" "+g.Tags["Black"])
+	}
+	want := []string{
+		"1 2019.06.01 Alpha, Anna 1-0 Beta, Ben",
+		"1 2019.06.01 Gamma, Gil 1-0 BYE",
+		"2 2019.06.02 Beta, Ben 1/2-1/2 BYE",
+		"2 2019.06.02 Gamma, Gil 1/2-1/2 Alpha, Anna",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Games() = %q, want %q", got, want)
+	}
+}
+
+func TestFile_roundDate(t *testing.T) {
+	tests := []struct {
+		name      string
+		startDate string
+		roundDate string
+		want      string
+	}{
+		{"century of the start date", "1998/06/01", "98/06/01", "1998.06.01"},
+		{"event over the new century", "1999/12/30", "00/01/02", "2000.01.02"},
+		{"no start date after 1968", "", "98/06/01", "1998.06.01"},
+		{"no start date before 1969", "", "19/06/01", "2019.06.01"},
+		{"start date without round dates", "1998/06/01", "", "1998.06.01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &File{StartDate: tt.startDate}
+			if tt.roundDate != "" {
+				f.RoundDates = []string{tt.roundDate}
+			}
+			if got := f.roundDate(1); got != tt.want {
+				t.Errorf("roundDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromGames(t *testing.T) {
+	f, err := Read(strings.NewReader(report))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	games := f.Games()
+	games[0].Tags["WhiteTitle"] = "GM"
+
+	back := FromGames(games)
+	if back.Name != "Winter Open" || back.City != "Hobart" || back.StartDate != "2019/06/01" || back.EndDate != "2019/06/02" {
+		t.Errorf("FromGames() headers = %+v", back)
+	}
+
+	// Gamma is unrated so is numbered after Beta
+	var rounds [][]Round
+	for _, p := range back.Players {
+		rounds = append(rounds, p.Rounds)
+	}
+	want := [][]Round{
+		{{2, tournament.White, Win}, {3, tournament.Black, Draw}},
+		{{1, tournament.Black, Loss}, {0, tournament.NoColour, HalfPointBye}},
+		{{0, tournament.NoColour, PairingBye}, {1, tournament.White, Draw}},
+	}
+	if !reflect.DeepEqual(rounds, want) {
+		t.Errorf("FromGames() rounds = %v, want %v", rounds, want)
+	}
+	if back.Players[0].Title != "GM" || back.Players[0].Points != 1.5 || back.Players[1].Rank != 3 {
+		t.Errorf("FromGames() Players[0] = %+v", back.Players[0])
+	}
+}
+
+func TestFromGames_unfinished(t *testing.T) {
+	games := []pgn.Game{
+		{Tags: map[string]string{"Event": "E", "Round": "1", "White": "A", "Black": "B", "Result": "1-0"}},
+		{Tags: map[string]string{"Event": "E", "Round": "2", "White": "B", "Black": "A", "Result": "*"}},
+	}
+	f := FromGames(games)
+	if len(f.Players) != 2 || len(f.Players[0].Rounds) != 1 {
+		t.Errorf("FromGames() = %+v", f.Players)
+	}
+}
@@ -0,0 +1,344 @@
+// Package uci is a client for chess engines that speak the Universal Chess Interface protocol.
+//
+// An Engine runs an external engine binary as a subprocess and talks to it over its standard
+// input and output. Moves are exchanged in long algebraic (coordinate) notation, ie. e2e4 or e7e8q.
+package uci
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout is how long an engine is given to answer a handshake, readiness check or stop
+// command before it is considered unresponsive.
+const DefaultTimeout = 10 * time.Second
+
+// ErrClosed is returned when the engine process has exited or been closed
+var ErrClosed = errors.New("uci: engine closed")
+
+// Engine is a running UCI engine. An Engine is not safe for concurrent use.
+type Engine struct {
+	// Name and Author are reported by the engine during the handshake
+	Name   string
+	Author string
+	// Options are the options advertised by the engine keyed by name
+	Options map[string]Option
+	// Timeout limits how long to wait for handshakes, readiness checks, stop and quit commands.
+	Timeout time.Duration
+
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+	lines chan string
+
+	// searching is set when a search was abandoned before the engine sent its best move
+	searching bool
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// GoParams are the limits of a search. Zero values are left out of the `go` command.
+type GoParams struct {
+	Depth     int
+	Nodes     int64
+	Mate      int
+	MoveTime  time.Duration
+	WTime     time.Duration
+	BTime     time.Duration
+	WInc      time.Duration
+	BInc      time.Duration
+	MovesToGo int
+	Infinite  bool
+	// SearchMoves restricts the search to the given moves
+	SearchMoves []string
+}
+
+// SearchResult is the outcome of a search
+type SearchResult struct {
+	BestMove string
+	// Ponder is the expected reply to the best move. It is empty if the engine didn't send one.
+	Ponder string
+	// Infos are all the info lines received during the search in order
+	Infos []Info
+}
+
+// Start launches the engine at path and performs the `uci` handshake.
+func Start(path string, args ...string) (*Engine, error) {
+	cmd := exec.Command(path, args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	e := &Engine{
+		Options: make(map[string]Option),
+		Timeout: DefaultTimeout,
+		cmd:     cmd,
+		stdin:   stdin,
+		lines:   make(chan string, 64),
+	}
+
+	go func() {
+		s := bufio.NewScanner(stdout)
+		s.Buffer(make([]byte, 64*1024), 1024*1024)
+		for s.Scan() {
+			e.lines <- strings.TrimSpace(s.Text())
+		}
+		close(e.lines)
+	}()
+
+	if err := e.handshake(); err != nil {
+		e.kill()
+		e.drain()
+		_ = cmd.Wait()
+		return nil, err
+	}
+
+	return e, nil
+}
+
+func (e *Engine) handshake() error {
+	if err := e.send("uci"); err != nil {
+		return err
+	}
+
+	return e.readUntil(e.Timeout, func(line string) bool {
+		switch {
+		case strings.HasPrefix(line, "id name "):
+			e.Name = strings.TrimPrefix(line, "id name ")
+		case strings.HasPrefix(line, "id author "):
+			e.Author = strings.TrimPrefix(line, "id author ")
+		case strings.HasPrefix(line, "option "):
+			if opt, err := parseOption(line); err == nil {
+				e.Options[opt.Name] = opt
+			}
+		case line == "uciok":
+			return true
+		}
+		return false
+	})
+}
+
+// SetOption sets an engine option. Button options are pressed by passing an empty value.
+func (e *Engine) SetOption(name, value string) error {
+	if value == "" {
+		return e.send("setoption name " + name)
+	}
+	return e.send("setoption name " + name + " value " + value)
+}
+
+// IsReady waits for the engine to answer `isready`
+func (e *Engine) IsReady() error {
+	if err := e.finishSearch(); err != nil {
+		return err
+	}
+	if err := e.send("isready"); err != nil {
+		return err
+	}
+	return e.readUntil(e.Timeout, func(line string) bool {
+		return line == "readyok"
+	})
+}
+
+// NewGame tells the engine the next position is from a different game
+func (e *Engine) NewGame() error {
+	if err := e.send("ucinewgame"); err != nil {
+		return err
+	}
+	return e.IsReady()
+}
+
+// Position sets the position to search. An empty fen means the standard start position. Moves
+// are in coordinate notation.
+func (e *Engine) Position(fen string, moves []string) error {
+	cmd := "position startpos"
+	if fen != "" {
+		cmd = "position fen " + fen
+	}
+	if len(moves) > 0 {
+		cmd += " moves " + strings.Join(moves, " ")
+	}
+	return e.send(cmd)
+}
+
+// Go starts a search and blocks until the engine sends its best move. If ctx is done before
+// the search finishes the engine is sent `stop` and the move it answers with is returned.
+// Infinite searches only end when ctx is done.
+func (e *Engine) Go(ctx context.Context, params GoParams) (SearchResult, error) {
+	var result SearchResult
+
+	if err := e.finishSearch(); err != nil {
+		return result, err
+	}
+	if err := e.send(params.command()); err != nil {
+		return result, err
+	}
+
+	done := ctx.Done()
+	var deadline <-chan time.Time
+	for {
+		select {
+		case line, ok := <-e.lines:
+			if !ok {
+				return result, ErrClosed
+			}
+			if strings.HasPrefix(line, "info ") {
+				if info, err := ParseInfo(line); err == nil {
+					result.Infos = append(result.Infos, info)
+				}
+			} else if strings.HasPrefix(line, "bestmove") {
+				fields := strings.Fields(line)
+				if len(fields) > 1 {
+					result.BestMove = fields[1]
+				}
+				if len(fields) > 3 && fields[2] == "ponder" {
+					result.Ponder = fields[3]
+				}
+				return result, nil
+			}
+		case <-done:
+			if err := e.send("stop"); err != nil {
+				return result, err
+			}
+			done = nil
+			deadline = time.After(e.Timeout)
+		case <-deadline:
+			// The best move may still arrive and must not be taken as the next search's
+			e.searching = true
+			return result, fmt.Errorf("uci: engine did not answer stop within %v", e.Timeout)
+		}
+	}
+}
+
+// finishSearch discards the output of an abandoned search up to its best move
+func (e *Engine) finishSearch() error {
+	if !e.searching {
+		return nil
+	}
+	err := e.readUntil(e.Timeout, func(line string) bool {
+		return strings.HasPrefix(line, "bestmove")
+	})
+	if err != nil {
+		return fmt.Errorf("uci: engine is still searching: %v", err)
+	}
+	e.searching = false
+	return nil
+}
+
+// Stop asks the engine to finish the current search as soon as possible. The best move is still
+// returned by the pending call to Go.
+func (e *Engine) Stop() error {
+	return e.send("stop")
+}
+
+// Close sends `quit` and waits for the engine to exit. Engines that don't exit within the timeout
+// are killed.
+func (e *Engine) Close() error {
+	e.closeOnce.Do(func() {
+		_ = e.send("quit")
+		_ = e.stdin.Close()
+
+		e.drain()
+		exited := make(chan error, 1)
+		go func() {
+			exited <- e.cmd.Wait()
+		}()
+
+		select {
+		case e.closeErr = <-exited:
+		case <-time.After(e.Timeout):
+			e.kill()
+			// Reap the killed process so it isn't left behind as a zombie
+			<-exited
+			e.closeErr = fmt.Errorf("uci: engine did not quit within %v and was killed", e.Timeout)
+		}
+	})
+
+	return e.closeErr
+}
+
+// drain discards the engine's output so it never blocks writing to a full pipe
+func (e *Engine) drain() {
+	go func() {
+		for range e.lines {
+		}
+	}()
+}
+
+func (e *Engine) kill() {
+	if e.cmd.Process != nil {
+		_ = e.cmd.Process.Kill()
+	}
+}
+
+func (e *Engine) send(cmd string) error {
+	_, err := io.WriteString(e.stdin, cmd+"\n")
+	return err
+}
+
+// readUntil reads lines from the engine until stop returns true or the timeout passes
+func (e *Engine) readUntil(timeout time.Duration, stop func(string) bool) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case line, ok := <-e.lines:
+			if !ok {
+				return ErrClosed
+			}
+			if stop(line) {
+				return nil
+			}
+		case <-timer.C:
+			return fmt.Errorf("uci: engine did not respond within %v", timeout)
+		}
+	}
+}
+
+func (p GoParams) command() string {
+	cmd := []string{"go"}
+
+	add := func(key string, v int64) {
+		if v > 0 {
+			cmd = append(cmd, key, strconv.FormatInt(v, 10))
+		}
+	}
+	ms := func(d time.Duration) int64 {
+		return int64(d / time.Millisecond)
+	}
+
+	if len(p.SearchMoves) > 0 {
+		cmd = append(cmd, "searchmoves")
+		cmd = append(cmd, p.SearchMoves...)
+	}
+	add("wtime", ms(p.WTime))
+	add("btime", ms(p.BTime))
+	add("winc", ms(p.WInc))
+	add("binc", ms(p.BInc))
+	add("movestogo", int64(p.MovesToGo))
+	add("depth", int64(p.Depth))
+	add("nodes", p.Nodes)
+	add("mate", int64(p.Mate))
+	add("movetime", ms(p.MoveTime))
+	if p.Infinite {
+		cmd = append(cmd, "infinite")
+	}
+
+	return strings.Join(cmd, " ")
+}
@@ -0,0 +1,191 @@
+package uci
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeEngine is the path of the fake engine binary built by TestMain
+var fakeEngine string
+
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "uci")
+	if err != nil {
+		panic(err)
+	}
+
+	fakeEngine = filepath.Join(dir, "fakeengine")
+	build := exec.Command("go", "build", "-o", fakeEngine, "./internal/fakeengine")
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		panic(err)
+	}
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func startFake(t *testing.T) *Engine {
+	e, err := Start(fakeEngine)
+	if err != nil {
+		t.Fatalf("Could not start fake engine: %v", err)
+	}
+	e.Timeout = 2 * time.Second
+	return e
+}
+
+func TestStart(t *testing.T) {
+	e := startFake(t)
+	defer e.Close()
+
+	if e.Name != "FakeEngine 1.0" {
+		t.Errorf("Name = %q, want %q", e.Name, "FakeEngine 1.0")
+	}
+	if e.Author != "go-chess" {
+		t.Errorf("Author = %q, want %q", e.Author, "go-chess")
+	}
+	if len(e.Options) != 4 {
+		t.Errorf("len(Options) = %v, want 4", len(e.Options))
+	}
+	if e.Options["Hash"].Max != 1024 {
+		t.Errorf("Hash option max = %v, want 1024", e.Options["Hash"].Max)
+	}
+
+	if err := e.SetOption("Hash", "32"); err != nil {
+		t.Errorf("SetOption() error = %v", err)
+	}
+	if err := e.NewGame(); err != nil {
+		t.Errorf("NewGame() error = %v", err)
+	}
+}
+
+func TestStartMissingBinary(t *testing.T) {
+	if _, err := Start(filepath.Join(os.TempDir(), "no-such-engine")); err == nil {
+		t.Errorf("Start() expected an error for a missing binary")
+	}
+}
+
+func TestStartNotAnEngine(t *testing.T) {
+	path, err := exec.LookPath("true")
+	if err != nil {
+		t.Skip("true is not available")
+	}
+	if _, err := Start(path); err == nil {
+		t.Errorf("Start() expected an error for a program that exits without a handshake")
+	}
+}
+
+func TestGoDepth(t *testing.T) {
+	e := startFake(t)
+	defer e.Close()
+
+	if err := e.Position("", []string{"e2e4"}); err != nil {
+		t.Fatalf("Position() error = %v", err)
+	}
+	result, err := e.Go(context.Background(), GoParams{Depth: 4})
+	if err != nil {
+		t.Fatalf("Go() error = %v", err)
+	}
+
+	if result.BestMove != "e7e5" || result.Ponder != "g1f3" {
+		t.Errorf("Go() best move = %v ponder %v, want e7e5 ponder g1f3", result.BestMove, result.Ponder)
+	}
+	if len(result.Infos) != 4 {
+		t.Fatalf("Go() returned %v infos, want 4", len(result.Infos))
+	}
+	last := result.Infos[3]
+	if last.Depth != 4 || last.Score.CP != 40 || last.WDL == nil || last.WDL.Win != 500 {
+		t.Errorf("Go() last info = %+v", last)
+	}
+}
+
+func TestGoInfiniteStopsOnContext(t *testing.T) {
+	e := startFake(t)
+	defer e.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	result, err := e.Go(ctx, GoParams{Infinite: true})
+	if err != nil {
+		t.Fatalf("Go() error = %v", err)
+	}
+	if result.BestMove != "e2e4" {
+		t.Errorf("Go() best move = %v, want e2e4", result.BestMove)
+	}
+}
+
+func TestGoAfterStopTimeout(t *testing.T) {
+	e := startFake(t)
+	defer e.Close()
+
+	if err := e.SetOption("StopDelay", "300"); err != nil {
+		t.Fatal(err)
+	}
+	e.Timeout = 50 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := e.Go(ctx, GoParams{Infinite: true}); err == nil {
+		t.Fatalf("Go() expected an error when stop isn't answered in time")
+	}
+
+	// The late best move of the first search is not returned for the second
+	e.Timeout = 2 * time.Second
+	if err := e.Position("", []string{"e2e4"}); err != nil {
+		t.Fatal(err)
+	}
+	result, err := e.Go(context.Background(), GoParams{Depth: 1})
+	if err != nil {
+		t.Fatalf("Go() error = %v", err)
+	}
+	if result.BestMove != "e7e5" || len(result.Infos) != 1 {
+		t.Errorf("Go() = %v with %v infos, want e7e5 with 1", result.BestMove, len(result.Infos))
+	}
+}
+
+func TestClose(t *testing.T) {
+	e := startFake(t)
+	if err := e.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	if err := e.IsReady(); err == nil {
+		t.Errorf("IsReady() expected an error after Close()")
+	}
+}
+
+func TestGoParamsCommand(t *testing.T) {
+	tests := []struct {
+		name   string
+		params GoParams
+		want   string
+	}{
+		{
+			name:   "Depth",
+			params: GoParams{Depth: 10},
+			want:   "go depth 10",
+		},
+		{
+			name:   "Clock",
+			params: GoParams{WTime: time.Minute, BTime: 30 * time.Second, WInc: time.Second, BInc: time.Second},
+			want:   "go wtime 60000 btime 30000 winc 1000 binc 1000",
+		},
+		{
+			name:   "Infinite with search moves",
+			params: GoParams{Infinite: true, SearchMoves: []string{"e2e4", "d2d4"}},
+			want:   "go searchmoves e2e4 d2d4 infinite",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.params.command(); got != tt.want {
+				t.Errorf("GoParams.command() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package uci
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Score is an engine evaluation from the point of view of the side to move
+type Score struct {
+	// CP is the score in centipawns. It is zero when the score is a mate score.
+	CP int
+	// Mate is the number of moves to mate. Negative values mean the engine is getting mated.
+	Mate int
+	// IsMate is true when the score is a mate score rather than a centipawn score
+	IsMate bool
+	// Lowerbound and Upperbound are set when the score is only a bound on the real value
+	Lowerbound bool
+	Upperbound bool
+}
+
+// WDL is the engine's estimate of win, draw and loss probabilities in permille
+type WDL struct {
+	Win  int
+	Draw int
+	Loss int
+}
+
+// Info is a single parsed `info` line sent by an engine while searching
+type Info struct {
+	Depth    int
+	SelDepth int
+	// MultiPV is the index of the line when searching multiple principal variations. Engines that
+	// don't send it are treated as sending multipv 1.
+	MultiPV  int
+	Score    Score
+	HasScore bool
+	// WDL is only present when the engine sends win/draw/loss statistics
+	WDL      *WDL
+	Nodes    int64
+	NPS      int64
+	TBHits   int64
+	HashFull int
+	Time     time.Duration
+	// PV is the principal variation in long algebraic (coordinate) notation
+	PV          []string
+	CurrMove    string
+	CurrMoveNum int
+	// String is any free text the engine sent with the `string` keyword
+	String string
+}
+
+// ParseInfo parses a line sent by an engine starting with `info`
+func ParseInfo(line string) (Info, error) {
+	info := Info{MultiPV: 1}
+	fields := strings.Fields(line)
+	if len(fields) == 0 || fields[0] != "info" {
+		return info, fmt.Errorf("not an info line: %q", line)
+	}
+
+	// next returns the integer following the keyword at position i
+	next := func(i int) (int64, error) {
+		if i+1 >= len(fields) {
+			return 0, fmt.Errorf("missing value for %q in info line: %q", fields[i], line)
+		}
+		n, err := strconv.ParseInt(fields[i+1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid value for %q in info line: %q", fields[i], line)
+		}
+		return n, nil
+	}
+
+	for i := 1; i < len(fields); i++ {
+		var n int64
+		var err error
+
+		switch fields[i] {
+		case "depth", "seldepth", "multipv", "nodes", "nps", "tbhits", "hashfull", "time", "currmovenumber":
+			n, err = next(i)
+			if err != nil {
+				return info, err
+			}
+			setInt(&info, fields[i], n)
+			i++
+		case "currmove":
+			if i+1 < len(fields) {
+				info.CurrMove = fields[i+1]
+				i++
+			}
+		case "score":
+			i, err = parseScore(&info, fields, i+1)
+			if err != nil {
+				return info, fmt.Errorf("%v in info line: %q", err, line)
+			}
+		case "wdl":
+			if i+3 >= len(fields) {
+				return info, fmt.Errorf("incomplete wdl in info line: %q", line)
+			}
+			var wdl WDL
+			for j, p := range []*int{&wdl.Win, &wdl.Draw, &wdl.Loss} {
+				v, err := strconv.Atoi(fields[i+1+j])
+				if err != nil {
+					return info, fmt.Errorf("invalid wdl in info line: %q", line)
+				}
+				*p = v
+			}
+			info.WDL = &wdl
+			i += 3
+		case "pv":
+			// pv runs until the next known keyword or the end of the line
+			j := i + 1
+			for ; j < len(fields) && !isInfoKeyword(fields[j]); j++ {
+			}
+			info.PV = append([]string(nil), fields[i+1:j]...)
+			i = j - 1
+		case "string":
+			info.String = strings.Join(fields[i+1:], " ")
+			return info, nil
+		}
+	}
+
+	return info, nil
+}
+
+func setInt(info *Info, key string, n int64) {
+	switch key {
+	case "depth":
+		info.Depth = int(n)
+	case "seldepth":
+		info.SelDepth = int(n)
+	case "multipv":
+		info.MultiPV = int(n)
+	case "nodes":
+		info.Nodes = n
+	case "nps":
+		info.NPS = n
+	case "tbhits":
+		info.TBHits = n
+	case "hashfull":
+		info.HashFull = int(n)
+	case "time":
+		info.Time = time.Duration(n) * time.Millisecond
+	case "currmovenumber":
+		info.CurrMoveNum = int(n)
+	}
+}
+
+// parseScore reads `cp <x>` or `mate <y>` and an optional bound starting at fields[i]. It returns
+// the index of the last field consumed.
+func parseScore(info *Info, fields []string, i int) (int, error) {
+	if i+1 >= len(fields) {
+		return i, fmt.Errorf("incomplete score")
+	}
+	v, err := strconv.Atoi(fields[i+1])
+	if err != nil {
+		return i, fmt.Errorf("invalid score %q", fields[i+1])
+	}
+
+	switch fields[i] {
+	case "cp":
+		info.Score.CP = v
+	case "mate":
+		info.Score.Mate = v
+		info.Score.IsMate = true
+	default:
+		return i, fmt.Errorf("unknown score type %q", fields[i])
+	}
+	info.HasScore = true
+	i++
+
+	if i+1 < len(fields) {
+		switch fields[i+1] {
+		case "lowerbound":
+			info.Score.Lowerbound = true
+			i++
+		case "upperbound":
+			info.Score.Upperbound = true
+			i++
+		}
+	}
+
+	return i, nil
+}
+
+func isInfoKeyword(s string) bool {
+	switch s {
+	case "depth", "seldepth", "time", "nodes", "pv", "multipv", "score", "currmove", "currmovenumber",
+		"hashfull", "nps", "tbhits", "sbhits", "cpuload", "string", "refutation", "currline", "wdl":
+		return true
+	}
+	return false
+}
+
+// Option is an engine option advertised during the `uci` handshake
+type Option struct {
+	Name    string
+	Type    string
+	Default string
+	Min     int
+	Max     int
+	// Vars are the allowed values of a combo option
+	Vars []string
+}
+
+// parseOption parses an `option name ... type ...` line. Option names and values may contain
+// spaces so each keyword's value runs until the next keyword.
+func parseOption(line string) (Option, error) {
+	var opt Option
+	fields := strings.Fields(line)
+	if len(fields) < 2 || fields[0] != "option" {
+		return opt, fmt.Errorf("not an option line: %q", line)
+	}
+
+	var key string
+	var value []string
+	flush := func() {
+		v := strings.Join(value, " ")
+		switch key {
+		case "name":
+			opt.Name = v
+		case "type":
+			opt.Type = v
+		case "default":
+			opt.Default = v
+		case "min":
+			opt.Min, _ = strconv.Atoi(v)
+		case "max":
+			opt.Max, _ = strconv.Atoi(v)
+		case "var":
+			opt.Vars = append(opt.Vars, v)
+		}
+		value = nil
+	}
+
+	for _, f := range fields[1:] {
+		switch f {
+		case "name", "type", "default", "min", "max", "var":
+			// Keywords may legitimately appear inside an option name
+			if key == "name" && f != "type" {
+				value = append(value, f)
+				continue
+			}
+			flush()
+			key = f
+		default:
+			value = append(value, f)
+		}
+	}
+	flush()
+
+	if opt.Name == "" {
+		return opt, fmt.Errorf("option without a name: %q", line)
+	}
+
+	return opt, nil
+}
@@ -0,0 +1,157 @@
+package uci
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    Info
+		wantErr bool
+	}{
+		{
+			name: "Centipawn score with pv",
+			line: "info depth 20 seldepth 28 multipv 1 score cp 35 nodes 123456 nps 987654 hashfull 120 tbhits 0 time 125 pv e2e4 e7e5 g1f3",
+			want: Info{
+				Depth:    20,
+				SelDepth: 28,
+				MultiPV:  1,
+				Score:    Score{CP: 35},
+				HasScore: true,
+				Nodes:    123456,
+				NPS:      987654,
+				HashFull: 120,
+				Time:     125 * time.Millisecond,
+				PV:       []string{"e2e4", "e7e5", "g1f3"},
+			},
+		},
+		{
+			name: "Mate score with wdl",
+			line: "info depth 12 multipv 2 score mate -3 wdl 0 0 1000 pv h7h8q",
+			want: Info{
+				Depth:    12,
+				MultiPV:  2,
+				Score:    Score{Mate: -3, IsMate: true},
+				HasScore: true,
+				WDL:      &WDL{Win: 0, Draw: 0, Loss: 1000},
+				PV:       []string{"h7h8q"},
+			},
+		},
+		{
+			name: "Bounded score",
+			line: "info depth 8 score cp -12 lowerbound nodes 10",
+			want: Info{
+				Depth:    8,
+				MultiPV:  1,
+				Score:    Score{CP: -12, Lowerbound: true},
+				HasScore: true,
+				Nodes:    10,
+			},
+		},
+		{
+			name: "PV followed by keywords",
+			line: "info pv d2d4 d7d5 depth 3",
+			want: Info{
+				Depth:   3,
+				MultiPV: 1,
+				PV:      []string{"d2d4", "d7d5"},
+			},
+		},
+		{
+			name: "Current move",
+			line: "info currmove e2e4 currmovenumber 1",
+			want: Info{
+				MultiPV:     1,
+				CurrMove:    "e2e4",
+				CurrMoveNum: 1,
+			},
+		},
+		{
+			name: "String",
+			line: "info string NNUE evaluation enabled",
+			want: Info{
+				MultiPV: 1,
+				String:  "NNUE evaluation enabled",
+			},
+		},
+		{
+			name:    "Bad number",
+			line:    "info depth x",
+			wantErr: true,
+		},
+		{
+			name:    "Unknown score type",
+			line:    "info score pawns 1",
+			wantErr: true,
+		},
+		{
+			name:    "Not an info line",
+			line:    "bestmove e2e4",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseInfo(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseInfo() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseInfo() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseOption(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    Option
+		wantErr bool
+	}{
+		{
+			name: "Spin",
+			line: "option name Hash type spin default 16 min 1 max 1024",
+			want: Option{Name: "Hash", Type: "spin", Default: "16", Min: 1, Max: 1024},
+		},
+		{
+			name: "Name with spaces",
+			line: "option name Clear Hash type button",
+			want: Option{Name: "Clear Hash", Type: "button"},
+		},
+		{
+			name: "Combo",
+			line: "option name Style type combo default Normal var Solid var Normal var Risky",
+			want: Option{Name: "Style", Type: "combo", Default: "Normal", Vars: []string{"Solid", "Normal", "Risky"}},
+		},
+		{
+			name:    "Missing name",
+			line:    "option type check",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseOption(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseOption() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseOption() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
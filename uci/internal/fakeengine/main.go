@@ -0,0 +1,98 @@
+// Command fakeengine is a minimal UCI engine used to test the uci package. It does not play
+// chess, it only speaks enough of the protocol to exercise a client.
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func main() {
+	in := bufio.NewScanner(os.Stdin)
+	lines := make(chan string)
+
+	go func() {
+		for in.Scan() {
+			lines <- in.Text()
+		}
+		close(lines)
+	}()
+
+	var moves []string
+	var stopDelay time.Duration
+	for line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "uci":
+			fmt.Println("id name FakeEngine 1.0")
+			fmt.Println("id author go-chess")
+			fmt.Println("option name Hash type spin default 16 min 1 max 1024")
+			fmt.Println("option name UCI_ShowWDL type check default false")
+			fmt.Println("option name Style type combo default Normal var Solid var Normal var Risky")
+			fmt.Println("option name Clear Hash type button")
+			fmt.Println("uciok")
+		case "setoption":
+			// StopDelay makes the engine slow to answer stop, in milliseconds
+			if len(fields) == 5 && fields[2] == "StopDelay" {
+				ms, _ := strconv.Atoi(fields[4])
+				stopDelay = time.Duration(ms) * time.Millisecond
+			}
+		case "isready":
+			fmt.Println("readyok")
+		case "position":
+			moves = nil
+			for i, f := range fields {
+				if f == "moves" {
+					moves = fields[i+1:]
+				}
+			}
+		case "go":
+			search(fields[1:], moves, lines, stopDelay)
+		case "quit":
+			return
+		}
+	}
+}
+
+// search prints an info line per depth followed by a best move. Infinite searches keep waiting
+// until a stop command arrives on lines and then for stopDelay.
+func search(args []string, moves []string, lines chan string, stopDelay time.Duration) {
+	depth := 3
+	infinite := false
+	for i, arg := range args {
+		switch arg {
+		case "depth":
+			depth, _ = strconv.Atoi(args[i+1])
+		case "infinite":
+			infinite = true
+		}
+	}
+
+	best := "e2e4"
+	if len(moves)%2 == 1 {
+		best = "e7e5"
+	}
+
+	for d := 1; d <= depth; d++ {
+		fmt.Printf("info depth %d seldepth %d multipv 1 score cp %d wdl 500 400 100 nodes %d nps 1000 time %d pv %s g1f3\n", d, d+2, 10*d, 100*d, d, best)
+	}
+
+	if infinite {
+		for line := range lines {
+			if line == "stop" {
+				break
+			}
+		}
+		time.Sleep(stopDelay)
+	}
+
+	fmt.Printf("bestmove %s ponder g1f3\n", best)
+}